@@ -2,6 +2,7 @@ package cachex
 
 import (
 	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/patrickmn/go-cache"
 	"time"
 )
 
@@ -81,6 +82,23 @@ func (c *memoryCache) Set(key string, value interface{}, ttl ...interface{}) boo
 	return true
 }
 
+func (c *memoryCache) SetIfAbsent(key string, value interface{}, ttl time.Duration) bool {
+	if gocache == nil {
+		return false
+	}
+
+	cacheKey := BuildCacheKey(key)
+	data := map[string]interface{}{"data": value}
+
+	if ttl > 0 {
+		data["expireAt"] = time.Now().Add(ttl).Unix()
+	} else {
+		ttl = cache.DefaultExpiration
+	}
+
+	return gocache.Add(cacheKey, data, ttl) == nil
+}
+
 func (c *memoryCache) Delete(key string) bool {
 	if gocache == nil {
 		return false
@@ -89,6 +89,28 @@ func (c *redisCache) Set(key string, value interface{}, ttl ...interface{}) bool
 	return err == nil
 }
 
+func (c *redisCache) SetIfAbsent(key string, value interface{}, ttl time.Duration) bool {
+	conn, err := c.getRedisConn()
+
+	if err != nil {
+		return false
+	}
+
+	defer conn.Close()
+	cacheKey := BuildCacheKey(key)
+	entry := map[string]interface{}{"data": value}
+	var reply interface{}
+
+	if ttl > 0 {
+		entry["expireAt"] = time.Now().Add(ttl).Unix()
+		reply, err = conn.Do("SET", cacheKey, jsonx.ToJson(entry), "PX", ttl.Milliseconds(), "NX")
+	} else {
+		reply, err = conn.Do("SET", cacheKey, jsonx.ToJson(entry), "NX")
+	}
+
+	return err == nil && reply != nil
+}
+
 func (c *redisCache) Delete(key string) bool {
 	conn, err := c.getRedisConn()
 
@@ -8,9 +8,14 @@ import (
 	"github.com/patrickmn/go-cache"
 	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
+type setIfAbsentCache interface {
+	SetIfAbsent(key string, value interface{}, ttl time.Duration) bool
+}
+
 var cacheDir string
 var cacheKeyPrefix string
 var cacheKeyRedismqNormal = "redismq.normal"
@@ -18,6 +23,7 @@ var cacheKeyRedismqDelayable = "redismq.delayable"
 var defaultCacheStore string
 var gocache *cache.Cache
 var cacheStores = map[string]ccachex.ICache{}
+var setIfAbsentLock = &sync.Mutex{}
 
 func CacheDir(dir ...string) string {
 	if len(dir) > 0 {
@@ -149,3 +155,18 @@ func Store(name string) ccachex.ICache {
 	fmt.Println("is noop cache")
 	return &noopCache{}
 }
+
+func SetIfAbsent(store ccachex.ICache, key string, value interface{}, ttl time.Duration) bool {
+	if c, ok := store.(setIfAbsentCache); ok {
+		return c.SetIfAbsent(key, value, ttl)
+	}
+
+	setIfAbsentLock.Lock()
+	defer setIfAbsentLock.Unlock()
+
+	if store.Has(key) {
+		return false
+	}
+
+	return store.Set(key, value, ttl)
+}
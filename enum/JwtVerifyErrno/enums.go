@@ -4,4 +4,5 @@ const (
 	NotFound = -1
 	Invalid = -2
 	Expired = -3
+	Revoked = -4
 )
@@ -0,0 +1,73 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+)
+
+type apiKeyAuthProvider struct {
+	headerName string
+	queryName  string
+	store      CredentialStore
+}
+
+func NewApiKeyAuthProvider(settings map[string]interface{}) *apiKeyAuthProvider {
+	headerName := "X-Api-Key"
+
+	if s1, ok := settings["header"].(string); ok {
+		headerName = s1
+	}
+
+	var store CredentialStore
+
+	if s1, ok := settings["store"].(CredentialStore); ok {
+		store = s1
+	}
+
+	return &apiKeyAuthProvider{
+		headerName: headerName,
+		queryName:  castx.ToString(settings["query"]),
+		store:      store,
+	}
+}
+
+func (p *apiKeyAuthProvider) GetSchemeName() string {
+	return "apikey"
+}
+
+func (p *apiKeyAuthProvider) Supports(req *Request) bool {
+	return p.getApiKey(req) != ""
+}
+
+func (p *apiKeyAuthProvider) Authenticate(req *Request) (string, error) {
+	apiKey := p.getApiKey(req)
+
+	if apiKey == "" {
+		return "", NewAuthenticationError(p.GetSchemeName(), "api key not found")
+	}
+
+	if p.store == nil {
+		return "", NewAuthenticationError(p.GetSchemeName(), "credential store not configured")
+	}
+
+	subject, ok := p.store.Lookup(apiKey)
+
+	if !ok {
+		return "", NewAuthenticationError(p.GetSchemeName(), "invalid api key")
+	}
+
+	return subject, nil
+}
+
+func (p *apiKeyAuthProvider) getApiKey(req *Request) string {
+	if p.headerName != "" {
+		if s1 := req.GetHeader(p.headerName); s1 != "" {
+			return s1
+		}
+	}
+
+	if p.queryName != "" {
+		return req.GetQueryParams()[p.queryName]
+	}
+
+	return ""
+}
@@ -0,0 +1,7 @@
+package mgboot
+
+type AuthProvider interface {
+	GetSchemeName() string
+	Supports(req *Request) bool
+	Authenticate(req *Request) (subject string, err error)
+}
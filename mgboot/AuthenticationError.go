@@ -0,0 +1,36 @@
+package mgboot
+
+type AuthenticationError struct {
+	scheme    string
+	reason    string
+	challenge string
+}
+
+func NewAuthenticationError(scheme, reason string) AuthenticationError {
+	return AuthenticationError{scheme: scheme, reason: reason}
+}
+
+func (ex AuthenticationError) Error() string {
+	if ex.scheme == "" {
+		return "authentication failed, " + ex.reason
+	}
+
+	return ex.scheme + " authentication failed, " + ex.reason
+}
+
+func (ex AuthenticationError) Scheme() string {
+	return ex.scheme
+}
+
+func (ex AuthenticationError) Reason() string {
+	return ex.reason
+}
+
+func (ex AuthenticationError) WithChallenge(challenge string) AuthenticationError {
+	ex.challenge = challenge
+	return ex
+}
+
+func (ex AuthenticationError) Challenge() string {
+	return ex.challenge
+}
@@ -0,0 +1,24 @@
+package mgboot
+
+type authenticationErrorHandler struct {
+}
+
+func NewAuthenticationErrorHandler() *authenticationErrorHandler {
+	return &authenticationErrorHandler{}
+}
+
+func (h *authenticationErrorHandler) GetErrorName() string {
+	return "builtin.AuthenticationError"
+}
+
+func (h *authenticationErrorHandler) MatchError(err error) bool {
+	if _, ok := err.(AuthenticationError); ok {
+		return true
+	}
+
+	return false
+}
+
+func (h *authenticationErrorHandler) HandleError(_ error) ResponsePayload {
+	return NewHttpErrorResponse(401)
+}
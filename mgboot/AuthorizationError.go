@@ -0,0 +1,27 @@
+package mgboot
+
+type AuthorizationError struct {
+	reason string
+}
+
+func NewAuthorizationError(reason ...string) AuthorizationError {
+	var _reason string
+
+	if len(reason) > 0 {
+		_reason = reason[0]
+	}
+
+	return AuthorizationError{reason: _reason}
+}
+
+func (ex AuthorizationError) Error() string {
+	if ex.reason == "" {
+		return "authorization failed"
+	}
+
+	return "authorization failed, " + ex.reason
+}
+
+func (ex AuthorizationError) Reason() string {
+	return ex.reason
+}
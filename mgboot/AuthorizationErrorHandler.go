@@ -0,0 +1,24 @@
+package mgboot
+
+type authorizationErrorHandler struct {
+}
+
+func NewAuthorizationErrorHandler() *authorizationErrorHandler {
+	return &authorizationErrorHandler{}
+}
+
+func (h *authorizationErrorHandler) GetErrorName() string {
+	return "builtin.AuthorizationError"
+}
+
+func (h *authorizationErrorHandler) MatchError(err error) bool {
+	if _, ok := err.(AuthorizationError); ok {
+		return true
+	}
+
+	return false
+}
+
+func (h *authorizationErrorHandler) HandleError(_ error) ResponsePayload {
+	return NewHttpErrorResponse(403)
+}
@@ -0,0 +1,97 @@
+package mgboot
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-common/util/stringx"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+)
+
+type basicAuthProvider struct {
+	realm string
+	store CredentialStore
+}
+
+func NewBasicAuthProvider(settings map[string]interface{}) *basicAuthProvider {
+	realm := castx.ToString(settings["realm"])
+
+	if realm == "" {
+		realm = "Restricted"
+	}
+
+	var store CredentialStore
+
+	if s1, ok := settings["store"].(CredentialStore); ok {
+		store = s1
+	}
+
+	return &basicAuthProvider{realm: realm, store: store}
+}
+
+func (p *basicAuthProvider) GetSchemeName() string {
+	return "basic"
+}
+
+func (p *basicAuthProvider) Supports(req *Request) bool {
+	s1 := strings.TrimSpace(req.GetHeader("Authorization"))
+	return strings.ToLower(stringx.SubstringBefore(s1, " ")) == "basic"
+}
+
+func (p *basicAuthProvider) Authenticate(req *Request) (string, error) {
+	username, password, ok := p.parseCredentials(req)
+
+	if !ok {
+		return "", p.fail(req, "malformed credentials")
+	}
+
+	if p.store == nil {
+		return "", p.fail(req, "credential store not configured")
+	}
+
+	hash, ok := p.store.Lookup(username)
+
+	if !ok || hash == "" {
+		return "", p.fail(req, "invalid username or password")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return "", p.fail(req, "invalid username or password")
+	}
+
+	return username, nil
+}
+
+func (p *basicAuthProvider) parseCredentials(req *Request) (string, string, bool) {
+	s1 := strings.TrimSpace(req.GetHeader("Authorization"))
+	s1 = strings.TrimSpace(stringx.SubstringAfter(s1, " "))
+	buf, err := base64.StdEncoding.DecodeString(s1)
+
+	if err != nil {
+		return "", "", false
+	}
+
+	idx := strings.Index(string(buf), ":")
+
+	if idx < 1 {
+		return "", "", false
+	}
+
+	return string(buf[:idx]), string(buf[idx+1:]), true
+}
+
+func (p *basicAuthProvider) fail(_ *Request, reason string) error {
+	challenge := fmt.Sprintf(`Basic realm="%s"`, p.realm)
+	return NewAuthenticationError(p.GetSchemeName(), reason).WithChallenge(challenge)
+}
+
+func HashBasicAuthPassword(password string) (string, error) {
+	buf, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(buf), nil
+}
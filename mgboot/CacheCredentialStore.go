@@ -0,0 +1,42 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-common/util/stringx"
+	"github.com/meiguonet/mgboot-go-gin/cachex"
+)
+
+type cacheCredentialStore struct {
+	storeName string
+	keyPrefix string
+}
+
+func NewCacheCredentialStore(storeName, keyPrefix string) *cacheCredentialStore {
+	if keyPrefix != "" {
+		keyPrefix = stringx.EnsureRight(keyPrefix, ".")
+	}
+
+	return &cacheCredentialStore{storeName: storeName, keyPrefix: keyPrefix}
+}
+
+func (s *cacheCredentialStore) Lookup(id string) (string, bool) {
+	if id == "" {
+		return "", false
+	}
+
+	value := castx.ToString(cachex.Store(s.storeName).Get(s.keyPrefix + id))
+
+	if value == "" {
+		return "", false
+	}
+
+	return value, true
+}
+
+func (s *cacheCredentialStore) Put(id, value string) bool {
+	return cachex.Store(s.storeName).Set(s.keyPrefix+id, value)
+}
+
+func (s *cacheCredentialStore) Remove(id string) bool {
+	return cachex.Store(s.storeName).Delete(s.keyPrefix + id)
+}
@@ -0,0 +1,74 @@
+package mgboot
+
+import (
+	ccachex "github.com/meiguonet/mgboot-go-common/cachex"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-gin/cachex"
+	"time"
+)
+
+type cacheJwtRevocationStore struct {
+	storeName string
+}
+
+func NewMemoryJwtRevocationStore() *cacheJwtRevocationStore {
+	return &cacheJwtRevocationStore{storeName: "memory"}
+}
+
+func NewFileJwtRevocationStore() *cacheJwtRevocationStore {
+	return &cacheJwtRevocationStore{storeName: "file"}
+}
+
+func NewRedisJwtRevocationStore() *cacheJwtRevocationStore {
+	return &cacheJwtRevocationStore{storeName: "redis"}
+}
+
+func (s *cacheJwtRevocationStore) RevokeJti(jti string, ttl time.Duration) bool {
+	if jti == "" {
+		return false
+	}
+
+	if ttl > 0 {
+		return s.store().Set("jwt.revoked.jti."+jti, 1, ttl)
+	}
+
+	return s.store().Set("jwt.revoked.jti."+jti, 1)
+}
+
+func (s *cacheJwtRevocationStore) IsJtiRevoked(jti string) bool {
+	if jti == "" {
+		return false
+	}
+
+	return castx.ToInt(s.store().Get("jwt.revoked.jti."+jti)) == 1
+}
+
+func (s *cacheJwtRevocationStore) RevokeSubject(sub string, before time.Time, ttl time.Duration) bool {
+	if sub == "" || before.IsZero() {
+		return false
+	}
+
+	if ttl > 0 {
+		return s.store().Set("jwt.revoked.sub."+sub, before.Unix(), ttl)
+	}
+
+	return s.store().Set("jwt.revoked.sub."+sub, before.Unix())
+}
+
+func (s *cacheJwtRevocationStore) SubjectRevokedBefore(sub string) time.Time {
+	if sub == "" {
+		return time.Time{}
+	}
+
+	n1 := castx.ToInt64(s.store().Get("jwt.revoked.sub." + sub))
+
+	if n1 < 1 {
+		return time.Time{}
+	}
+
+	return time.Unix(n1, 0)
+}
+
+func (s *cacheJwtRevocationStore) store() ccachex.ICache {
+	return cachex.Store(s.storeName)
+}
@@ -0,0 +1,230 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ConcurrencyLimiterStats struct {
+	Name       string  `json:"name"`
+	Limit      int     `json:"limit"`
+	InFlight   int     `json:"inFlight"`
+	Queued     int     `json:"queued"`
+	Accepted   int64   `json:"accepted"`
+	Rejected   int64   `json:"rejected"`
+	TimedOut   int64   `json:"timedOut"`
+	AvgLatency float64 `json:"avgLatencyMs"`
+	MinLatency float64 `json:"minLatencyMs"`
+	Adaptive   string  `json:"adaptive"`
+	MaxQueue   int     `json:"maxQueue"`
+	RetryAfter int     `json:"retryAfter"`
+}
+
+type ConcurrencyLimiter struct {
+	mu            sync.Mutex
+	name          string
+	limit         float64
+	minLimit      int
+	maxLimit      int
+	maxQueue      int
+	queueTimeout  time.Duration
+	retryAfter    int
+	adaptive      string
+	targetLatency time.Duration
+	inFlight      int
+	waiters       []chan struct{}
+	accepted      int64
+	rejected      int64
+	timedOut      int64
+	avgLatency    float64
+	minLatency    float64
+}
+
+func NewConcurrencyLimiter(name string, settings map[string]interface{}) *ConcurrencyLimiter {
+	limit := castx.ToInt(settings["maxConcurrent"])
+
+	if limit < 1 {
+		limit = 100
+	}
+
+	minLimit := castx.ToInt(settings["minLimit"])
+
+	if minLimit < 1 {
+		minLimit = 1
+	}
+
+	maxLimit := castx.ToInt(settings["maxLimit"])
+
+	if maxLimit < limit {
+		maxLimit = limit
+	}
+
+	var queueTimeout time.Duration
+
+	if d1, ok := settings["queueTimeout"].(time.Duration); ok && d1 > 0 {
+		queueTimeout = d1
+	} else if n1 := castx.ToInt64(settings["queueTimeout"]); n1 > 0 {
+		queueTimeout = time.Duration(n1) * time.Millisecond
+	}
+
+	var targetLatency time.Duration
+
+	if d1, ok := settings["targetLatency"].(time.Duration); ok && d1 > 0 {
+		targetLatency = d1
+	} else if n1 := castx.ToInt64(settings["targetLatency"]); n1 > 0 {
+		targetLatency = time.Duration(n1) * time.Millisecond
+	}
+
+	retryAfter := castx.ToInt(settings["retryAfter"])
+
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+
+	adaptive := strings.ToLower(castx.ToString(settings["adaptive"]))
+
+	if adaptive != "aimd" && adaptive != "gradient" {
+		adaptive = ""
+	}
+
+	return &ConcurrencyLimiter{
+		name:          name,
+		limit:         float64(limit),
+		minLimit:      minLimit,
+		maxLimit:      maxLimit,
+		maxQueue:      castx.ToInt(settings["maxQueue"]),
+		queueTimeout:  queueTimeout,
+		retryAfter:    retryAfter,
+		adaptive:      adaptive,
+		targetLatency: targetLatency,
+		waiters:       make([]chan struct{}, 0),
+	}
+}
+
+func (l *ConcurrencyLimiter) Name() string {
+	return l.name
+}
+
+func (l *ConcurrencyLimiter) RetryAfter() int {
+	return l.retryAfter
+}
+
+func (l *ConcurrencyLimiter) Acquire() (release func(), ok bool) {
+	l.mu.Lock()
+
+	if l.inFlight < int(l.limit) {
+		l.inFlight++
+		l.accepted++
+		l.mu.Unlock()
+		return l.releaseFunc(time.Now()), true
+	}
+
+	if len(l.waiters) >= l.maxQueue || l.queueTimeout < 1 {
+		l.rejected++
+		l.mu.Unlock()
+		return nil, false
+	}
+
+	ch := make(chan struct{})
+	l.waiters = append(l.waiters, ch)
+	l.mu.Unlock()
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-ch:
+		return l.releaseFunc(time.Now()), true
+	case <-timer.C:
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for idx, waiter := range l.waiters {
+		if waiter == ch {
+			l.waiters = append(l.waiters[:idx], l.waiters[idx+1:]...)
+			l.timedOut++
+			l.rejected++
+			return nil, false
+		}
+	}
+
+	return l.releaseFunc(time.Now()), true
+}
+
+func (l *ConcurrencyLimiter) Stats() ConcurrencyLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return ConcurrencyLimiterStats{
+		Name:       l.name,
+		Limit:      int(l.limit),
+		InFlight:   l.inFlight,
+		Queued:     len(l.waiters),
+		Accepted:   l.accepted,
+		Rejected:   l.rejected,
+		TimedOut:   l.timedOut,
+		AvgLatency: l.avgLatency,
+		MinLatency: l.minLatency,
+		Adaptive:   l.adaptive,
+		MaxQueue:   l.maxQueue,
+		RetryAfter: l.retryAfter,
+	}
+}
+
+func (l *ConcurrencyLimiter) releaseFunc(startAt time.Time) func() {
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			l.release(time.Since(startAt))
+		})
+	}
+}
+
+func (l *ConcurrencyLimiter) release(latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	saturated := l.inFlight >= int(l.limit)
+	l.inFlight--
+	ms := float64(latency) / float64(time.Millisecond)
+
+	if l.avgLatency <= 0 {
+		l.avgLatency = ms
+	} else {
+		l.avgLatency = l.avgLatency*0.9 + ms*0.1
+	}
+
+	if l.minLatency <= 0 || ms < l.minLatency {
+		l.minLatency = ms
+	}
+
+	switch l.adaptive {
+	case "aimd":
+		if l.targetLatency > 0 && latency > l.targetLatency {
+			l.limit = l.limit * 0.9
+		} else if saturated {
+			l.limit++
+		}
+	case "gradient":
+		if l.avgLatency > 0 {
+			gradient := math.Max(0.5, math.Min(1, l.minLatency/l.avgLatency))
+			l.limit = l.limit*gradient + math.Sqrt(l.limit)
+		}
+	}
+
+	if l.adaptive != "" {
+		l.limit = math.Max(float64(l.minLimit), math.Min(float64(l.maxLimit), l.limit))
+	}
+
+	for len(l.waiters) > 0 && l.inFlight < int(l.limit) {
+		ch := l.waiters[0]
+		l.waiters = l.waiters[1:]
+		l.inFlight++
+		l.accepted++
+		close(ch)
+	}
+}
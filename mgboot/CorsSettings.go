@@ -2,11 +2,15 @@ package mgboot
 
 import (
 	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-common/util/slicex"
+	"regexp"
+	"strings"
 	"time"
 )
 
 type CorsSettings struct {
 	allowedOrigins   []string
+	originPatterns   []*regexp.Regexp
 	allowedHeaders   []string
 	allowedMethods   []string
 	allowCredentials bool
@@ -68,11 +72,38 @@ func NewCorsSettings(settings map[string]interface{}) *CorsSettings {
 		maxAge = castx.ToDuration(s1)
 	}
 
+	allowCredentials := castx.ToBool(settings["allowCredentials"])
+
+	if allowCredentials && slicex.InStringSlice("*", allowedOrigins) {
+		RuntimeLogger().Warn("in mgboot.NewCorsSettings, allowCredentials can not be used with wildcard origin, ignored")
+		allowCredentials = false
+	}
+
+	originPatterns := make([]*regexp.Regexp, 0)
+
+	for _, origin := range allowedOrigins {
+		var pattern string
+
+		if strings.HasPrefix(origin, "regex:") {
+			pattern = strings.TrimPrefix(origin, "regex:")
+		} else if strings.Contains(origin, "://*.") {
+			parts := strings.SplitN(origin, "://*.", 2)
+			pattern = "^" + regexp.QuoteMeta(parts[0]) + `://[^/]+\.` + regexp.QuoteMeta(strings.TrimRight(parts[1], "/")) + "$"
+		} else {
+			continue
+		}
+
+		if re, err := regexp.Compile(pattern); err == nil {
+			originPatterns = append(originPatterns, re)
+		}
+	}
+
 	return &CorsSettings{
 		allowedOrigins:   allowedOrigins,
+		originPatterns:   originPatterns,
 		allowedHeaders:   allowedHeaders,
 		allowedMethods:   allowedMethods,
-		allowCredentials: castx.ToBool(settings["allowCredentials"]),
+		allowCredentials: allowCredentials,
 		exposedHeaders:   exposedHeaders,
 		maxAge:           maxAge,
 	}
@@ -101,3 +132,57 @@ func (st *CorsSettings) ExposedHeaders() []string {
 func (st *CorsSettings) MaxAge() time.Duration {
 	return st.maxAge
 }
+
+func (st *CorsSettings) AllowAnyOrigin() bool {
+	return slicex.InStringSlice("*", st.allowedOrigins)
+}
+
+func (st *CorsSettings) MatchOrigin(origin string) bool {
+	if origin == "" || origin == "null" {
+		return false
+	}
+
+	if st.AllowAnyOrigin() {
+		return true
+	}
+
+	return st.MatchOriginStrictly(origin)
+}
+
+func (st *CorsSettings) MatchOriginStrictly(origin string) bool {
+	for _, allowed := range st.allowedOrigins {
+		if allowed != "*" && strings.EqualFold(strings.TrimRight(allowed, "/"), origin) {
+			return true
+		}
+	}
+
+	for _, re := range st.originPatterns {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (st *CorsSettings) IsMethodAllowed(method string) bool {
+	if slicex.InStringSlice("*", st.allowedMethods) {
+		return true
+	}
+
+	return slicex.InStringSlice(strings.ToUpper(method), st.allowedMethods, true)
+}
+
+func (st *CorsSettings) AreHeadersAllowed(headerNames []string) bool {
+	if slicex.InStringSlice("*", st.allowedHeaders) {
+		return true
+	}
+
+	for _, name := range headerNames {
+		if !slicex.InStringSlice(name, st.allowedHeaders, true) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,5 @@
+package mgboot
+
+type CredentialStore interface {
+	Lookup(id string) (string, bool)
+}
@@ -0,0 +1,17 @@
+package mgboot
+
+type CsrfError struct {
+	reason string
+}
+
+func NewCsrfError(reason string) CsrfError {
+	return CsrfError{reason: reason}
+}
+
+func (ex CsrfError) Error() string {
+	return "csrf check failed, " + ex.reason
+}
+
+func (ex CsrfError) Reason() string {
+	return ex.reason
+}
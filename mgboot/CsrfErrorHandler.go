@@ -0,0 +1,24 @@
+package mgboot
+
+type csrfErrorHandler struct {
+}
+
+func NewCsrfErrorHandler() *csrfErrorHandler {
+	return &csrfErrorHandler{}
+}
+
+func (h *csrfErrorHandler) GetErrorName() string {
+	return "builtin.CsrfError"
+}
+
+func (h *csrfErrorHandler) MatchError(err error) bool {
+	if _, ok := err.(CsrfError); ok {
+		return true
+	}
+
+	return false
+}
+
+func (h *csrfErrorHandler) HandleError(_ error) ResponsePayload {
+	return NewHttpErrorResponse(403)
+}
@@ -0,0 +1,143 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type CsrfSettings struct {
+	mode         string
+	cookieName   string
+	headerName   string
+	formField    string
+	cookiePath   string
+	cookieDomain string
+	secure       bool
+	sameSite     http.SameSite
+	ttl          time.Duration
+	store        string
+	exemptPaths  []string
+	checkOrigin  bool
+}
+
+func NewCsrfSettings(settings map[string]interface{}) *CsrfSettings {
+	mode := strings.ToLower(castx.ToString(settings["mode"]))
+
+	if mode != "synchronizer" {
+		mode = "double-submit"
+	}
+
+	cookieName := "XSRF-TOKEN"
+
+	if s1 := castx.ToString(settings["cookieName"]); s1 != "" {
+		cookieName = s1
+	}
+
+	headerName := "X-XSRF-TOKEN"
+
+	if s1 := castx.ToString(settings["headerName"]); s1 != "" {
+		headerName = s1
+	}
+
+	formField := "_csrf"
+
+	if s1 := castx.ToString(settings["formField"]); s1 != "" {
+		formField = s1
+	}
+
+	cookiePath := "/"
+
+	if s1 := castx.ToString(settings["cookiePath"]); s1 != "" {
+		cookiePath = s1
+	}
+
+	var sameSite http.SameSite
+
+	switch strings.ToLower(castx.ToString(settings["sameSite"])) {
+	case "strict":
+		sameSite = http.SameSiteStrictMode
+	case "none":
+		sameSite = http.SameSiteNoneMode
+	default:
+		sameSite = http.SameSiteLaxMode
+	}
+
+	ttl := 2 * time.Hour
+
+	if d1, ok := settings["ttl"].(time.Duration); ok && d1 > 0 {
+		ttl = d1
+	} else if s1, ok := settings["ttl"].(string); ok && s1 != "" {
+		ttl = castx.ToDuration(s1)
+	}
+
+	checkOrigin := true
+
+	if b1, err := castx.ToBoolE(settings["checkOrigin"]); err == nil && settings["checkOrigin"] != nil {
+		checkOrigin = b1
+	}
+
+	return &CsrfSettings{
+		mode:         mode,
+		cookieName:   cookieName,
+		headerName:   headerName,
+		formField:    formField,
+		cookiePath:   cookiePath,
+		cookieDomain: castx.ToString(settings["cookieDomain"]),
+		secure:       castx.ToBool(settings["secure"]),
+		sameSite:     sameSite,
+		ttl:          ttl,
+		store:        castx.ToString(settings["store"]),
+		exemptPaths:  castx.ToStringSlice(settings["exemptPaths"]),
+		checkOrigin:  checkOrigin,
+	}
+}
+
+func (st *CsrfSettings) Mode() string {
+	return st.mode
+}
+
+func (st *CsrfSettings) CookieName() string {
+	return st.cookieName
+}
+
+func (st *CsrfSettings) HeaderName() string {
+	return st.headerName
+}
+
+func (st *CsrfSettings) FormField() string {
+	return st.formField
+}
+
+func (st *CsrfSettings) CookiePath() string {
+	return st.cookiePath
+}
+
+func (st *CsrfSettings) CookieDomain() string {
+	return st.cookieDomain
+}
+
+func (st *CsrfSettings) Secure() bool {
+	return st.secure
+}
+
+func (st *CsrfSettings) SameSite() http.SameSite {
+	return st.sameSite
+}
+
+func (st *CsrfSettings) Ttl() time.Duration {
+	return st.ttl
+}
+
+func (st *CsrfSettings) Store() string {
+	return st.store
+}
+
+func (st *CsrfSettings) ExemptPaths() []string {
+	return st.exemptPaths
+}
+
+func (st *CsrfSettings) CheckOrigin() bool {
+	return st.checkOrigin
+}
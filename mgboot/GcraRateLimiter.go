@@ -0,0 +1,70 @@
+package mgboot
+
+import (
+	"sync"
+	"time"
+)
+
+type gcraRateLimiter struct {
+	mu       sync.Mutex
+	total    int
+	duration time.Duration
+	tats     map[string]time.Time
+}
+
+func NewGcraRateLimiter(total int, duration time.Duration) *gcraRateLimiter {
+	return &gcraRateLimiter{
+		total:    total,
+		duration: duration,
+		tats:     map[string]time.Time{},
+	}
+}
+
+func (l *gcraRateLimiter) GetAlgorithmName() string {
+	return "gcra"
+}
+
+func (l *gcraRateLimiter) Allow(key string) RateLimitResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := rateLimiterNow()
+	interval := l.duration / time.Duration(l.total)
+	tat, ok := l.tats[key]
+
+	if !ok {
+		l.cleanup(now)
+	}
+
+	if tat.Before(now) {
+		tat = now
+	}
+
+	newTat := tat.Add(interval)
+	allowAt := newTat.Add(-l.duration)
+	result := RateLimitResult{Limit: l.total}
+
+	if now.Before(allowAt) {
+		result.RetryAfter = allowAt.Sub(now)
+		result.ResetAfter = tat.Sub(now)
+		result.Remaining = 0
+		return result
+	}
+
+	l.tats[key] = newTat
+	result.Allowed = true
+	result.ResetAfter = newTat.Sub(now)
+	result.Remaining = int((l.duration - newTat.Sub(now)) / interval)
+	return result
+}
+
+func (l *gcraRateLimiter) cleanup(now time.Time) {
+	if len(l.tats) < 10000 {
+		return
+	}
+
+	for key, tat := range l.tats {
+		if tat.Before(now) {
+			delete(l.tats, key)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package mgboot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-gin/cachex"
+	"strings"
+	"time"
+)
+
+type hmacAuthProvider struct {
+	store      CredentialStore
+	maxSkew    time.Duration
+	nonceStore string
+}
+
+func NewHmacAuthProvider(settings map[string]interface{}) *hmacAuthProvider {
+	var store CredentialStore
+
+	if s1, ok := settings["store"].(CredentialStore); ok {
+		store = s1
+	}
+
+	maxSkew := 5 * time.Minute
+
+	if d1, ok := settings["maxSkew"].(time.Duration); ok && d1 > 0 {
+		maxSkew = d1
+	} else if s1, ok := settings["maxSkew"].(string); ok && s1 != "" {
+		maxSkew = castx.ToDuration(s1)
+	}
+
+	nonceStore := castx.ToString(settings["nonceStore"])
+
+	if nonceStore == "" {
+		nonceStore = cachex.DefaultStore()
+	}
+
+	return &hmacAuthProvider{
+		store:      store,
+		maxSkew:    maxSkew,
+		nonceStore: nonceStore,
+	}
+}
+
+func (p *hmacAuthProvider) GetSchemeName() string {
+	return "hmac"
+}
+
+func (p *hmacAuthProvider) Supports(req *Request) bool {
+	return req.GetHeader("X-Signature-Key") != "" && req.GetHeader("X-Signature") != ""
+}
+
+func (p *hmacAuthProvider) Authenticate(req *Request) (string, error) {
+	keyId := req.GetHeader("X-Signature-Key")
+	signature := strings.ToLower(req.GetHeader("X-Signature"))
+	timestamp := req.GetHeader("X-Signature-Timestamp")
+	nonce := req.GetHeader("X-Signature-Nonce")
+
+	if keyId == "" || signature == "" || timestamp == "" || nonce == "" {
+		return "", NewAuthenticationError(p.GetSchemeName(), "missing signature headers")
+	}
+
+	ts, err := castx.ToInt64E(timestamp)
+
+	if err != nil {
+		return "", NewAuthenticationError(p.GetSchemeName(), "invalid timestamp")
+	}
+
+	skew := time.Now().Sub(time.Unix(ts, 0))
+
+	if skew > p.maxSkew || skew < -p.maxSkew {
+		return "", NewAuthenticationError(p.GetSchemeName(), "timestamp out of range")
+	}
+
+	if p.store == nil {
+		return "", NewAuthenticationError(p.GetSchemeName(), "credential store not configured")
+	}
+
+	secret, ok := p.store.Lookup(keyId)
+
+	if !ok || secret == "" {
+		return "", NewAuthenticationError(p.GetSchemeName(), "invalid key")
+	}
+
+	expected := BuildHmacSignature(secret, req.GetMethod(), req.ctx.Request.URL.RequestURI(), timestamp, nonce, p.readBody(req))
+
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return "", NewAuthenticationError(p.GetSchemeName(), "signature mismatch")
+	}
+
+	nonceKey := "hmac.nonce." + keyId + "." + nonce
+	store := cachex.Store(p.nonceStore)
+
+	if !cachex.SetIfAbsent(store, nonceKey, 1, 2*p.maxSkew) {
+		if store.Has(nonceKey) {
+			return "", NewAuthenticationError(p.GetSchemeName(), "replayed request")
+		}
+
+		return "", NewAuthenticationError(p.GetSchemeName(), "nonce store unavailable")
+	}
+
+	return keyId, nil
+}
+
+func (p *hmacAuthProvider) readBody(req *Request) []byte {
+	buf, _ := readRawBody(req.ctx)
+	return buf
+}
+
+func BuildHmacSignature(secret, method, requestUri, timestamp, nonce string, body []byte) string {
+	bodyDigest := sha256.Sum256(body)
+
+	contents := strings.Join([]string{
+		strings.ToUpper(method),
+		requestUri,
+		timestamp,
+		nonce,
+		hex.EncodeToString(bodyDigest[:]),
+	}, "\n")
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(contents))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,78 @@
+package mgboot
+
+import (
+	"net"
+	"strings"
+)
+
+type IpFilter struct {
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+func NewIpFilter(settings map[string]interface{}) *IpFilter {
+	return &IpFilter{
+		allow: parseCidrList(toAuthorizeStringSlice(settings["allow"])),
+		deny:  parseCidrList(toAuthorizeStringSlice(settings["deny"])),
+	}
+}
+
+func (f *IpFilter) Allows(ip string) bool {
+	addr := net.ParseIP(strings.TrimSpace(ip))
+
+	if addr == nil {
+		return len(f.allow) < 1 && len(f.deny) < 1
+	}
+
+	if ipInNets(addr, f.deny) {
+		return false
+	}
+
+	if len(f.allow) < 1 {
+		return true
+	}
+
+	return ipInNets(addr, f.allow)
+}
+
+func parseCidrList(entries []string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(entries))
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+
+			if ip == nil {
+				continue
+			}
+
+			if ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+
+		if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+			nets = append(nets, ipNet)
+		}
+	}
+
+	return nets
+}
+
+func ipInNets(ip net.IP, nets []*net.IPNet) bool {
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
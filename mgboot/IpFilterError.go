@@ -0,0 +1,17 @@
+package mgboot
+
+type IpFilterError struct {
+	ip string
+}
+
+func NewIpFilterError(ip string) IpFilterError {
+	return IpFilterError{ip: ip}
+}
+
+func (ex IpFilterError) Error() string {
+	return "access denied for ip: " + ex.ip
+}
+
+func (ex IpFilterError) Ip() string {
+	return ex.ip
+}
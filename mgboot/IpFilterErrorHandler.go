@@ -0,0 +1,24 @@
+package mgboot
+
+type ipFilterErrorHandler struct {
+}
+
+func NewIpFilterErrorHandler() *ipFilterErrorHandler {
+	return &ipFilterErrorHandler{}
+}
+
+func (h *ipFilterErrorHandler) GetErrorName() string {
+	return "builtin.IpFilterError"
+}
+
+func (h *ipFilterErrorHandler) MatchError(err error) bool {
+	if _, ok := err.(IpFilterError); ok {
+		return true
+	}
+
+	return false
+}
+
+func (h *ipFilterErrorHandler) HandleError(_ error) ResponsePayload {
+	return NewHttpErrorResponse(403)
+}
@@ -1,6 +1,6 @@
 package mgboot
 
-import "github.com/meiguonet/mgboot-go-fiber/enum/JwtVerifyErrno"
+import "github.com/meiguonet/mgboot-go-gin/enum/JwtVerifyErrno"
 
 type jwtAuthErrorHandler struct {
 }
@@ -36,6 +36,9 @@ func (h *jwtAuthErrorHandler) HandleError(err error) ResponsePayload {
 	case JwtVerifyErrno.Expired:
 		code = 1003
 		msg = "安全令牌已失效"
+	case JwtVerifyErrno.Revoked:
+		code = 1004
+		msg = "安全令牌已被撤销"
 	}
 
 	payload := map[string]interface{}{
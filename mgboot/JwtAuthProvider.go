@@ -0,0 +1,83 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/enum/RegexConst"
+	"github.com/meiguonet/mgboot-go-common/util/stringx"
+	"github.com/meiguonet/mgboot-go-gin/enum/JwtVerifyErrno"
+	"strings"
+)
+
+type jwtAuthProvider struct {
+	settingsKey string
+}
+
+func NewJwtAuthProvider(settingsKey string) *jwtAuthProvider {
+	return &jwtAuthProvider{settingsKey: settingsKey}
+}
+
+func (p *jwtAuthProvider) GetSchemeName() string {
+	return "jwt"
+}
+
+func (p *jwtAuthProvider) Supports(req *Request) bool {
+	s1 := strings.TrimSpace(req.GetHeader("Authorization"))
+
+	if s1 == "" {
+		return false
+	}
+
+	if !strings.Contains(s1, " ") {
+		return true
+	}
+
+	return strings.ToLower(stringx.SubstringBefore(s1, " ")) == "bearer"
+}
+
+func (p *jwtAuthProvider) Authenticate(req *Request) (string, error) {
+	if p.settingsKey == "" {
+		return "", NewAuthenticationError(p.GetSchemeName(), "jwt settings key not specified")
+	}
+
+	settings := GetJwtSettings(p.settingsKey)
+
+	if settings == nil {
+		return "", NewAuthenticationError(p.GetSchemeName(), "jwt settings not found: "+p.settingsKey)
+	}
+
+	token := strings.TrimSpace(req.GetHeader("Authorization"))
+	token = stringx.RegexReplace(token, RegexConst.SpaceSep, " ")
+
+	if strings.Contains(token, " ") {
+		token = stringx.SubstringAfter(token, " ")
+	}
+
+	if token == "" {
+		return "", NewJwtAuthError(JwtVerifyErrno.NotFound)
+	}
+
+	tk, _ := ParseJsonWebToken(token, settings.PublicKeyPemFile())
+
+	if tk == nil {
+		return "", NewJwtAuthError(JwtVerifyErrno.Invalid)
+	}
+
+	errno := VerifyJsonWebToken(tk, settings)
+
+	if errno < 0 {
+		return "", NewJwtAuthError(errno)
+	}
+
+	principal, err := decodeJwtPrincipal(tk, settings)
+
+	if err != nil {
+		return "", NewJwtAuthError(JwtVerifyErrno.Invalid)
+	}
+
+	req.ctx.Set("JwtToken", tk)
+
+	if principal != nil {
+		req.ctx.Set("JwtPrincipal", principal)
+	}
+
+	return JwtClaimString(tk, "sub"), nil
+}
@@ -0,0 +1,10 @@
+package mgboot
+
+import "time"
+
+type JwtRevocationStore interface {
+	RevokeJti(jti string, ttl time.Duration) bool
+	IsJtiRevoked(jti string) bool
+	RevokeSubject(sub string, before time.Time, ttl time.Duration) bool
+	SubjectRevokedBefore(sub string) time.Time
+}
@@ -2,6 +2,7 @@ package mgboot
 
 import (
 	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"reflect"
 	"time"
 )
 
@@ -11,6 +12,7 @@ type JwtSettings struct {
 	refreshTokenTtl   time.Duration
 	publicKeyPemFile  string
 	privateKeyPemFile string
+	claimsType        reflect.Type
 }
 
 func NewJwtSettings(settings map[string]interface{}) *JwtSettings {
@@ -44,6 +46,7 @@ func NewJwtSettings(settings map[string]interface{}) *JwtSettings {
 		refreshTokenTtl:   refreshTokenTtl,
 		publicKeyPemFile:  castx.ToString(settings["publicKeyPemFile"]),
 		privateKeyPemFile: castx.ToString(settings["privateKeyPemFile"]),
+		claimsType:        toClaimsType(settings["claimsType"]),
 	}
 }
 
@@ -66,3 +69,7 @@ func (st *JwtSettings) PublicKeyPemFile() string {
 func (st *JwtSettings) PrivateKeyPemFile() string {
 	return st.privateKeyPemFile
 }
+
+func (st *JwtSettings) ClaimsType() reflect.Type {
+	return st.claimsType
+}
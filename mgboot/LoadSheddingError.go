@@ -0,0 +1,33 @@
+package mgboot
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+)
+
+type LoadSheddingError struct {
+	name       string
+	retryAfter int
+}
+
+func NewLoadSheddingError(name string, retryAfter int) LoadSheddingError {
+	return LoadSheddingError{name: name, retryAfter: retryAfter}
+}
+
+func (ex LoadSheddingError) Error() string {
+	return "server is overloaded: " + ex.name
+}
+
+func (ex LoadSheddingError) Name() string {
+	return ex.name
+}
+
+func (ex LoadSheddingError) RetryAfter() int {
+	return ex.retryAfter
+}
+
+func (ex LoadSheddingError) AddSpecifyHeaders(ctx *gin.Context) {
+	if ex.retryAfter > 0 {
+		ctx.Header("Retry-After", fmt.Sprintf("%d", ex.retryAfter))
+	}
+}
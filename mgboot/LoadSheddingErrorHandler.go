@@ -0,0 +1,24 @@
+package mgboot
+
+type loadSheddingErrorHandler struct {
+}
+
+func NewLoadSheddingErrorHandler() *loadSheddingErrorHandler {
+	return &loadSheddingErrorHandler{}
+}
+
+func (h *loadSheddingErrorHandler) GetErrorName() string {
+	return "builtin.LoadSheddingError"
+}
+
+func (h *loadSheddingErrorHandler) MatchError(err error) bool {
+	if _, ok := err.(LoadSheddingError); ok {
+		return true
+	}
+
+	return false
+}
+
+func (h *loadSheddingErrorHandler) HandleError(_ error) ResponsePayload {
+	return NewHttpErrorResponse(503)
+}
@@ -0,0 +1,110 @@
+package mgboot
+
+import (
+	"github.com/go-errors/errors"
+	"github.com/meiguonet/mgboot-go-common/util/fsx"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type localUploadStorage struct {
+	dir     string
+	baseUrl string
+}
+
+func NewLocalUploadStorage(dir string, baseUrl ...string) *localUploadStorage {
+	var _baseUrl string
+
+	if len(baseUrl) > 0 {
+		_baseUrl = strings.TrimRight(baseUrl[0], "/")
+	}
+
+	return &localUploadStorage{dir: fsx.GetRealpath(dir), baseUrl: _baseUrl}
+}
+
+func (s *localUploadStorage) Save(key string, reader io.Reader, _ int64, _ string) error {
+	fpath, err := s.resolve(key)
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+		return err
+	}
+
+	tmpPath := fpath + ".part"
+	fd, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(fd, reader); err != nil {
+		fd.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := fd.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Rename(tmpPath, fpath)
+}
+
+func (s *localUploadStorage) Exists(key string) bool {
+	fpath, err := s.resolve(key)
+
+	if err != nil {
+		return false
+	}
+
+	stat, err := os.Stat(fpath)
+	return err == nil && !stat.IsDir()
+}
+
+func (s *localUploadStorage) Open(key string) (io.ReadCloser, error) {
+	fpath, err := s.resolve(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(fpath)
+}
+
+func (s *localUploadStorage) Delete(key string) error {
+	fpath, err := s.resolve(key)
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(fpath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *localUploadStorage) Url(key string) string {
+	if s.baseUrl == "" {
+		return ""
+	}
+
+	return s.baseUrl + "/" + strings.TrimLeft(key, "/")
+}
+
+func (s *localUploadStorage) resolve(key string) (string, error) {
+	fpath := filepath.Join(s.dir, filepath.FromSlash(strings.TrimLeft(key, "/")))
+
+	if !strings.HasPrefix(fpath, filepath.Clean(s.dir)+string(filepath.Separator)) {
+		return "", errors.New("in mgboot.localUploadStorage, invalid key: " + key)
+	}
+
+	return fpath, nil
+}
@@ -0,0 +1,37 @@
+package mgboot
+
+import "sync"
+
+type mapCredentialStore struct {
+	mu      sync.RWMutex
+	entries map[string]string
+}
+
+func NewMapCredentialStore(entries map[string]string) *mapCredentialStore {
+	map1 := map[string]string{}
+
+	for id, value := range entries {
+		map1[id] = value
+	}
+
+	return &mapCredentialStore{entries: map1}
+}
+
+func (s *mapCredentialStore) Lookup(id string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.entries[id]
+	return value, ok
+}
+
+func (s *mapCredentialStore) Put(id, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[id] = value
+}
+
+func (s *mapCredentialStore) Remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, id)
+}
@@ -0,0 +1,61 @@
+package mgboot
+
+import (
+	"bytes"
+	"github.com/go-errors/errors"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+type memoryUploadStorage struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+func NewMemoryUploadStorage() *memoryUploadStorage {
+	return &memoryUploadStorage{files: map[string][]byte{}}
+}
+
+func (s *memoryUploadStorage) Save(key string, reader io.Reader, _ int64, _ string) error {
+	buf, err := ioutil.ReadAll(reader)
+
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[key] = buf
+	return nil
+}
+
+func (s *memoryUploadStorage) Exists(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.files[key]
+	return ok
+}
+
+func (s *memoryUploadStorage) Open(key string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	buf, ok := s.files[key]
+
+	if !ok {
+		return nil, errors.New("in mgboot.memoryUploadStorage, file not found: " + key)
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(buf)), nil
+}
+
+func (s *memoryUploadStorage) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.files, key)
+	return nil
+}
+
+func (s *memoryUploadStorage) Url(_ string) string {
+	return ""
+}
@@ -0,0 +1,72 @@
+package mgboot
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/enum/RegexConst"
+	"regexp"
+	"strings"
+)
+
+func MidAuthenticate(schemes ...string) gin.HandlerFunc {
+	names := make([]string, 0)
+	regex1 := regexp.MustCompile(RegexConst.CommaSep)
+
+	for _, s1 := range schemes {
+		for _, name := range regex1.Split(strings.TrimSpace(s1), -1) {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return func(ctx *gin.Context) {
+		if AppConf.GetBoolean("logging.logMiddlewareRun") {
+			RuntimeLogger().Info("middleware run: mgboot.MidAuthenticate")
+		}
+
+		if len(names) < 1 {
+			ctx.Next()
+			return
+		}
+
+		req := NewRequest(ctx)
+		var lastErr error
+		challenges := make([]string, 0)
+
+		for _, name := range names {
+			provider := GetAuthProvider(name)
+
+			if provider == nil || !provider.Supports(req) {
+				continue
+			}
+
+			subject, err := provider.Authenticate(req)
+
+			if err != nil {
+				lastErr = err
+
+				if ex, ok := err.(AuthenticationError); ok && ex.Challenge() != "" {
+					challenges = append(challenges, ex.Challenge())
+				}
+
+				continue
+			}
+
+			ctx.Set("AuthScheme", provider.GetSchemeName())
+			ctx.Set("AuthSubject", subject)
+			ctx.Next()
+			return
+		}
+
+		for _, challenge := range challenges {
+			ctx.Writer.Header().Add("WWW-Authenticate", challenge)
+		}
+
+		if lastErr != nil {
+			panic(lastErr)
+		}
+
+		panic(NewAuthenticationError("", "credentials not found"))
+	}
+}
@@ -0,0 +1,190 @@
+package mgboot
+
+import (
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-common/util/jsonx"
+	"github.com/meiguonet/mgboot-go-common/util/slicex"
+	"strings"
+)
+
+type AuthorizePredicate func(req *Request) bool
+
+type authorizeRequirement struct {
+	anyRoles         []string
+	allPermissions   []string
+	scopes           []string
+	claims           map[string]interface{}
+	predicates       []AuthorizePredicate
+	predicateNames   []string
+	rolesClaim       string
+	permissionsClaim string
+	scopeClaim       string
+}
+
+func MidAuthorize(settings interface{}) gin.HandlerFunc {
+	var map1 map[string]interface{}
+
+	if m1, ok := settings.(map[string]interface{}); ok && len(m1) > 0 {
+		map1 = m1
+	} else if s1, ok := settings.(string); ok && s1 != "" {
+		s1 = strings.ReplaceAll(s1, "[syh]", `"`)
+		map1 = jsonx.MapFrom(s1)
+	}
+
+	requirement := newAuthorizeRequirement(map1)
+
+	return func(ctx *gin.Context) {
+		if AppConf.GetBoolean("logging.logMiddlewareRun") {
+			RuntimeLogger().Info("middleware run: mgboot.MidAuthorize")
+		}
+
+		if requirement.isEmpty() {
+			ctx.Next()
+			return
+		}
+
+		req := NewRequest(ctx)
+
+		if reason := requirement.check(req, GetVerifiedJwt(ctx)); reason != "" {
+			panic(NewAuthorizationError(reason))
+		}
+
+		ctx.Next()
+	}
+}
+
+func newAuthorizeRequirement(settings map[string]interface{}) *authorizeRequirement {
+	rolesClaim := "roles"
+
+	if s1 := castx.ToString(settings["rolesClaim"]); s1 != "" {
+		rolesClaim = s1
+	}
+
+	permissionsClaim := "permissions"
+
+	if s1 := castx.ToString(settings["permissionsClaim"]); s1 != "" {
+		permissionsClaim = s1
+	}
+
+	scopeClaim := "scope"
+
+	if s1 := castx.ToString(settings["scopeClaim"]); s1 != "" {
+		scopeClaim = s1
+	}
+
+	predicates := make([]AuthorizePredicate, 0)
+	predicateNames := make([]string, 0)
+
+	switch t := settings["predicate"].(type) {
+	case AuthorizePredicate:
+		predicates = append(predicates, t)
+	case func(req *Request) bool:
+		predicates = append(predicates, t)
+	default:
+		predicateNames = toAuthorizeStringSlice(t)
+	}
+
+	return &authorizeRequirement{
+		anyRoles:         toAuthorizeStringSlice(settings["anyRoles"]),
+		allPermissions:   toAuthorizeStringSlice(settings["allPermissions"]),
+		scopes:           toAuthorizeStringSlice(settings["scopes"]),
+		claims:           castx.ToStringMap(settings["claims"]),
+		predicates:       predicates,
+		predicateNames:   predicateNames,
+		rolesClaim:       rolesClaim,
+		permissionsClaim: permissionsClaim,
+		scopeClaim:       scopeClaim,
+	}
+}
+
+func (r *authorizeRequirement) isEmpty() bool {
+	return len(r.anyRoles) < 1 &&
+		len(r.allPermissions) < 1 &&
+		len(r.scopes) < 1 &&
+		len(r.claims) < 1 &&
+		len(r.predicates) < 1 &&
+		len(r.predicateNames) < 1
+}
+
+func (r *authorizeRequirement) check(req *Request, token *jwt.Token) string {
+	claims := jwt.MapClaims{}
+
+	if token != nil {
+		if mc, ok := token.Claims.(jwt.MapClaims); ok {
+			claims = mc
+		}
+	}
+
+	if len(r.anyRoles) > 0 {
+		roles := toAuthorizeStringSlice(claims[r.rolesClaim])
+		var matched bool
+
+		for _, role := range r.anyRoles {
+			if slicex.InStringSlice(role, roles) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return "missing required role"
+		}
+	}
+
+	if len(r.allPermissions) > 0 {
+		permissions := toAuthorizeStringSlice(claims[r.permissionsClaim])
+
+		for _, permission := range r.allPermissions {
+			if !slicex.InStringSlice(permission, permissions) {
+				return "missing required permission: " + permission
+			}
+		}
+	}
+
+	if len(r.scopes) > 0 {
+		scopes := toAuthorizeStringSlice(claims[r.scopeClaim])
+
+		for _, scope := range r.scopes {
+			if !slicex.InStringSlice(scope, scopes) {
+				return "missing required scope: " + scope
+			}
+		}
+	}
+
+	for claimName, expected := range r.claims {
+		if castx.ToString(claims[claimName]) != castx.ToString(expected) {
+			return "claim mismatch: " + claimName
+		}
+	}
+
+	for _, fn := range r.predicates {
+		if !fn(req) {
+			return "predicate rejected"
+		}
+	}
+
+	for _, name := range r.predicateNames {
+		fn := GetAuthorizePredicate(name)
+
+		if fn == nil {
+			return "unknown predicate: " + name
+		}
+
+		if !fn(req) {
+			return "predicate rejected: " + name
+		}
+	}
+
+	return ""
+}
+
+func toAuthorizeStringSlice(arg0 interface{}) []string {
+	if s1, ok := arg0.(string); ok {
+		return strings.Fields(strings.ReplaceAll(s1, ",", " "))
+	}
+
+	return castx.ToStringSlice(arg0)
+}
@@ -0,0 +1,50 @@
+package mgboot
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/jsonx"
+	"strings"
+)
+
+// @param map[string]interface{}|string settings
+func MidConcurrencyLimit(name string, settings interface{}) gin.HandlerFunc {
+	map1, _ := settings.(map[string]interface{})
+
+	if s1, ok := settings.(string); ok && s1 != "" {
+		s1 = strings.ReplaceAll(s1, "[syh]", `"`)
+		map1 = jsonx.MapFrom(s1)
+	}
+
+	var limiter *ConcurrencyLimiter
+
+	if name != "" {
+		limiter = WithConcurrencyLimiter(name, map1)
+	}
+
+	return func(ctx *gin.Context) {
+		if AppConf.GetBoolean("logging.logMiddlewareRun") {
+			RuntimeLogger().Info("middleware run: mgboot.MidConcurrencyLimit")
+		}
+
+		if limiter == nil {
+			ctx.Next()
+			return
+		}
+
+		release, ok := limiter.Acquire()
+
+		if !ok {
+			panic(NewLoadSheddingError(limiter.Name(), limiter.RetryAfter()))
+		}
+
+		defer release()
+		ctx.Next()
+	}
+}
+
+func ConcurrencyStatsHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(200, GetConcurrencyLimiterStats())
+	}
+}
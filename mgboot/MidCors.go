@@ -0,0 +1,54 @@
+package mgboot
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/jsonx"
+	"strings"
+)
+
+func MidCors(arg0 interface{}) gin.HandlerFunc {
+	var settings *CorsSettings
+
+	if st, ok := arg0.(*CorsSettings); ok && st != nil {
+		settings = st
+	} else if map1, ok := arg0.(map[string]interface{}); ok && len(map1) > 0 {
+		settings = NewCorsSettings(map1)
+	} else if s1, ok := arg0.(string); ok && s1 != "" {
+		s1 = strings.ReplaceAll(s1, "[syh]", `"`)
+
+		if map1 := jsonx.MapFrom(s1); len(map1) > 0 {
+			settings = NewCorsSettings(map1)
+		}
+	}
+
+	return func(ctx *gin.Context) {
+		if AppConf.GetBoolean("logging.logMiddlewareRun") {
+			RuntimeLogger().Info("middleware run: mgboot.MidCors")
+		}
+
+		if settings == nil {
+			ctx.Next()
+			return
+		}
+
+		ctx.Set("CorsSettings", settings)
+
+		if IsCorsPreflight(ctx) {
+			AddPoweredBy(ctx)
+			AddSecurityHeaders(ctx)
+
+			if !CheckCorsPreflight(ctx) {
+				ctx.Writer.Header().Add("Vary", "Origin")
+				ctx.AbortWithStatus(403)
+				return
+			}
+
+			AddCorsSupport(ctx)
+			ctx.AbortWithStatus(204)
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,174 @@
+package mgboot
+
+import (
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-common/util/slicex"
+	"github.com/meiguonet/mgboot-go-gin/cachex"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func MidCsrf() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if AppConf.GetBoolean("logging.logMiddlewareRun") {
+			RuntimeLogger().Info("middleware run: mgboot.MidCsrf")
+		}
+
+		settings := GetCsrfSettings()
+
+		if settings == nil || isCsrfExemptPath(ctx.Request.URL.Path, settings.ExemptPaths()) {
+			ctx.Next()
+			return
+		}
+
+		req := NewRequest(ctx)
+		expected := ensureCsrfToken(ctx, settings)
+		safeMethods := []string{"GET", "HEAD", "OPTIONS", "TRACE"}
+
+		if slicex.InStringSlice(req.GetMethod(), safeMethods) {
+			ctx.Next()
+			return
+		}
+
+		if settings.CheckOrigin() {
+			if reason := checkCsrfOrigin(req); reason != "" {
+				panic(NewCsrfError(reason))
+			}
+		}
+
+		submitted := req.GetHeader(settings.HeaderName())
+
+		if submitted == "" {
+			submitted = req.ctx.PostForm(settings.FormField())
+		}
+
+		if submitted == "" {
+			panic(NewCsrfError("token not found"))
+		}
+
+		if expected == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(submitted)) != 1 {
+			panic(NewCsrfError("token mismatch"))
+		}
+
+		ctx.Next()
+	}
+}
+
+func ensureCsrfToken(ctx *gin.Context, settings *CsrfSettings) string {
+	maxAge := int(settings.Ttl().Seconds())
+
+	if settings.Mode() == "synchronizer" {
+		idCookieName := settings.CookieName() + "-ID"
+		id, _ := ctx.Cookie(idCookieName)
+		store := cachex.Store(csrfStoreName(settings))
+		var token string
+
+		if id != "" {
+			token = castx.ToString(store.Get("csrf." + id))
+		}
+
+		if token == "" {
+			id = randomHexString(16)
+			token = randomHexString(32)
+			store.Set("csrf."+id, token, settings.Ttl())
+			setCsrfCookie(ctx, settings, idCookieName, id, maxAge, true)
+		}
+
+		ctx.Set("CsrfToken", token)
+		return token
+	}
+
+	token, _ := ctx.Cookie(settings.CookieName())
+
+	if token == "" {
+		token = randomHexString(32)
+		setCsrfCookie(ctx, settings, settings.CookieName(), token, maxAge, false)
+
+		if !slicex.InStringSlice(strings.ToUpper(ctx.Request.Method), []string{"GET", "HEAD", "OPTIONS", "TRACE"}) {
+			ctx.Set("CsrfToken", token)
+			return ""
+		}
+	}
+
+	ctx.Set("CsrfToken", token)
+	return token
+}
+
+func setCsrfCookie(ctx *gin.Context, settings *CsrfSettings, name, value string, maxAge int, httpOnly bool) {
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     name,
+		Value:    url.QueryEscape(value),
+		MaxAge:   maxAge,
+		Path:     settings.CookiePath(),
+		Domain:   settings.CookieDomain(),
+		Secure:   settings.Secure(),
+		HttpOnly: httpOnly,
+		SameSite: settings.SameSite(),
+	})
+}
+
+func csrfStoreName(settings *CsrfSettings) string {
+	if settings.Store() != "" {
+		return settings.Store()
+	}
+
+	return cachex.DefaultStore()
+}
+
+func checkCsrfOrigin(req *Request) string {
+	origin := req.GetHeader("Origin")
+
+	if origin == "" {
+		referer := req.GetHeader("Referer")
+
+		if referer == "" {
+			return ""
+		}
+
+		u1, err := url.Parse(referer)
+
+		if err != nil || u1.Host == "" {
+			return "invalid referer"
+		}
+
+		origin = u1.Scheme + "://" + u1.Host
+	}
+
+	if origin == "null" {
+		return "opaque origin"
+	}
+
+	u1, err := url.Parse(origin)
+
+	if err != nil || u1.Host == "" {
+		return "invalid origin"
+	}
+
+	if strings.EqualFold(u1.Host, req.ctx.Request.Host) {
+		return ""
+	}
+
+	if settings := resolveCorsSettings(req.ctx); settings != nil && settings.MatchOriginStrictly(origin) {
+		return ""
+	}
+
+	return "cross-site origin: " + origin
+}
+
+func isCsrfExemptPath(path string, exemptPaths []string) bool {
+	for _, pattern := range exemptPaths {
+		if strings.HasSuffix(pattern, "*") && strings.HasPrefix(path, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+
+		if pattern == path {
+			return true
+		}
+	}
+
+	return false
+}
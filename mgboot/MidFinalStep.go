@@ -12,9 +12,14 @@ func MidFinalStep() gin.HandlerFunc {
 			RuntimeLogger().Info("middleware run: mgboot.MidFinalResult")
 		}
 
+		if IsRequestTimedOut(ctx) {
+			return
+		}
+
 		LogExecuteTime(ctx)
 		AddCorsSupport(ctx)
 		AddPoweredBy(ctx)
+		AddSecurityHeaders(ctx)
 		v1, _ := ctx.Get("ResponsePayload")
 		var payload ResponsePayload
 
@@ -49,6 +54,10 @@ func MidFinalStep() gin.HandlerFunc {
 			return
 		}
 
+		if pl, ok := payload.(PageResponse); ok {
+			pl.AddSpecifyHeaders(ctx)
+		}
+
 		if pl, ok := payload.(ImageResponse); ok {
 			ctx.Render(200, render.Data{
 				ContentType: pl.GetContentType(),
@@ -58,6 +67,11 @@ func MidFinalStep() gin.HandlerFunc {
 			return
 		}
 
+		if addConditionalHeaders(ctx, payload, contents) {
+			ctx.AbortWithStatus(304)
+			return
+		}
+
 		ctx.Render(200, render.Data{
 			ContentType: payload.GetContentType(),
 			Data:        []byte(contents),
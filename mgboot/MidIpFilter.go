@@ -0,0 +1,55 @@
+package mgboot
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/jsonx"
+	"strings"
+)
+
+// @param map[string]interface{}|string arg0
+func MidIpFilter(arg0 interface{}) gin.HandlerFunc {
+	var name string
+	var filter *IpFilter
+
+	if map1, ok := arg0.(map[string]interface{}); ok && len(map1) > 0 {
+		filter = NewIpFilter(map1)
+	} else if s1, ok := arg0.(string); ok && s1 != "" {
+		s1 = strings.ReplaceAll(s1, "[syh]", `"`)
+
+		if strings.HasPrefix(strings.TrimSpace(s1), "{") {
+			filter = NewIpFilter(jsonx.MapFrom(s1))
+		} else {
+			name = s1
+		}
+	}
+
+	return func(ctx *gin.Context) {
+		if AppConf.GetBoolean("logging.logMiddlewareRun") {
+			RuntimeLogger().Info("middleware run: mgboot.MidIpFilter")
+		}
+
+		_filter := filter
+		ip := NewRequest(ctx).GetClientIp()
+
+		if name != "" {
+			_filter = GetIpFilter(name)
+
+			if _filter == nil {
+				RuntimeLogger().Errorf("in mgboot.MidIpFilter, ip filter not found: %s", name)
+				panic(NewIpFilterError(ip))
+			}
+		}
+
+		if _filter == nil {
+			ctx.Next()
+			return
+		}
+
+		if !_filter.Allows(ip) {
+			panic(NewIpFilterError(ip))
+		}
+
+		ctx.Next()
+	}
+}
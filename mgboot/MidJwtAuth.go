@@ -3,47 +3,32 @@ package mgboot
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/meiguonet/mgboot-go-common/AppConf"
-	"github.com/meiguonet/mgboot-go-common/enum/RegexConst"
-	"github.com/meiguonet/mgboot-go-common/util/stringx"
-	"github.com/meiguonet/mgboot-go-fiber/enum/JwtVerifyErrno"
-	"strings"
 )
 
-func MidJwtAuth(settingsKey string) gin.HandlerFunc {
+// MidJwtAuth lets requests through when settingsKey is empty or no JwtSettings
+// are registered under it, pass failClosed=true to reject them with 401 instead
+func MidJwtAuth(settingsKey string, failClosed ...bool) gin.HandlerFunc {
+	provider := NewJwtAuthProvider(settingsKey)
+	_failClosed := len(failClosed) > 0 && failClosed[0]
+
 	return func(ctx *gin.Context) {
 		if AppConf.GetBoolean("logging.logMiddlewareRun") {
 			RuntimeLogger().Info("middleware run: mgboot.MidJwtAuth")
 		}
 
-		if settingsKey == "" {
-			ctx.Next()
-			return
-		}
-
-		settings := GetJwtSettings(settingsKey)
-
-		if settings == nil {
+		if !_failClosed && (settingsKey == "" || GetJwtSettings(settingsKey) == nil) {
 			ctx.Next()
 			return
 		}
 
-		token := strings.TrimSpace(ctx.GetHeader("Authorization"))
-		token = stringx.RegexReplace(token, RegexConst.SpaceSep, " ")
-
-		if strings.Contains(token, " ") {
-			token = stringx.SubstringAfter(token, " ")
-		}
-
-		if token == "" {
-			panic(NewJwtAuthError(JwtVerifyErrno.NotFound))
-		}
-
-		errno := VerifyJsonWebToken(token, settings)
+		subject, err := provider.Authenticate(NewRequest(ctx))
 
-		if errno < 0 {
-			panic(NewJwtAuthError(errno))
+		if err != nil {
+			panic(err)
 		}
 
+		ctx.Set("AuthScheme", provider.GetSchemeName())
+		ctx.Set("AuthSubject", subject)
 		ctx.Next()
 	}
 }
@@ -0,0 +1,68 @@
+package mgboot
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+)
+
+func MidOidcAuth(settingsKey string) gin.HandlerFunc {
+	provider := NewOidcAuthProvider(settingsKey)
+
+	return func(ctx *gin.Context) {
+		if AppConf.GetBoolean("logging.logMiddlewareRun") {
+			RuntimeLogger().Info("middleware run: mgboot.MidOidcAuth")
+		}
+
+		subject, err := provider.Authenticate(NewRequest(ctx))
+
+		if err != nil {
+			panic(err)
+		}
+
+		ctx.Set("AuthScheme", provider.GetSchemeName())
+		ctx.Set("AuthSubject", subject)
+		ctx.Next()
+	}
+}
+
+func OidcLoginHandler(settingsKey string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		provider := GetOidcProvider(settingsKey)
+
+		if provider == nil {
+			ctx.AbortWithStatus(404)
+			return
+		}
+
+		authUrl, err := provider.AuthCodeUrl(ctx.Query("returnTo"))
+
+		if err != nil {
+			RuntimeLogger().Error(err)
+			ctx.AbortWithStatus(502)
+			return
+		}
+
+		ctx.Redirect(302, authUrl)
+	}
+}
+
+func OidcHandleCallback(ctx *gin.Context, settingsKey string) (*OidcLoginResult, error) {
+	provider := GetOidcProvider(settingsKey)
+
+	if provider == nil {
+		return nil, NewAuthenticationError("oidc", "oidc provider not configured")
+	}
+
+	if errCode := ctx.Query("error"); errCode != "" {
+		return nil, NewAuthenticationError("oidc", errCode)
+	}
+
+	result, err := provider.Exchange(ctx.Query("state"), ctx.Query("code"))
+
+	if err != nil {
+		RuntimeLogger().Debugf("oidc login failed: %s", err.Error())
+		return nil, NewAuthenticationError("oidc", "login failed")
+	}
+
+	return result, nil
+}
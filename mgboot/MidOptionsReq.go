@@ -18,8 +18,22 @@ func MidOptionsReq() gin.HandlerFunc {
 			return
 		}
 
-		AddCorsSupport(ctx)
 		AddPoweredBy(ctx)
+		AddSecurityHeaders(ctx)
+
+		if IsCorsPreflight(ctx) {
+			if !CheckCorsPreflight(ctx) {
+				ctx.Writer.Header().Add("Vary", "Origin")
+				ctx.AbortWithStatus(403)
+				return
+			}
+
+			AddCorsSupport(ctx)
+			ctx.AbortWithStatus(204)
+			return
+		}
+
+		AddCorsSupport(ctx)
 
 		ctx.Render(200, render.Data{
 			ContentType: "application/json; charset=utf-8",
@@ -0,0 +1,58 @@
+package mgboot
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-common/util/jsonx"
+	"strings"
+)
+
+func MidPolicy(settings interface{}) gin.HandlerFunc {
+	var action string
+	var resource string
+
+	if map1, ok := settings.(map[string]interface{}); ok && len(map1) > 0 {
+		action = castx.ToString(map1["action"])
+		resource = castx.ToString(map1["resource"])
+	} else if s1, ok := settings.(string); ok && s1 != "" {
+		s1 = strings.ReplaceAll(s1, "[syh]", `"`)
+		map1 := jsonx.MapFrom(s1)
+
+		if len(map1) > 0 {
+			action = castx.ToString(map1["action"])
+			resource = castx.ToString(map1["resource"])
+		}
+	}
+
+	return func(ctx *gin.Context) {
+		if AppConf.GetBoolean("logging.logMiddlewareRun") {
+			RuntimeLogger().Info("middleware run: mgboot.MidPolicy")
+		}
+
+		req := NewRequest(ctx)
+		_action := action
+
+		if _action == "" {
+			_action = strings.ToLower(req.GetMethod())
+		}
+
+		_resource := resource
+
+		if _resource == "" {
+			_resource = ctx.Request.URL.Path
+		}
+
+		for _, p := range ctx.Params {
+			_resource = strings.ReplaceAll(_resource, "{"+p.Key+"}", p.Value)
+		}
+
+		decision := EvaluatePolicies(req, _action, _resource)
+
+		if !decision.Allowed() {
+			panic(NewAuthorizationError(decision.Reason()))
+		}
+
+		ctx.Next()
+	}
+}
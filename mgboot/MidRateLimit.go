@@ -1,53 +1,71 @@
 package mgboot
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/meiguonet/mgboot-go-common/AppConf"
 	"github.com/meiguonet/mgboot-go-common/util/castx"
 	"github.com/meiguonet/mgboot-go-common/util/jsonx"
-	"github.com/meiguonet/mgboot-go-dal/ratelimiter"
 	"strings"
 	"time"
 )
 
+type rateLimitRule struct {
+	suffix  string
+	policy  string
+	limiter RateLimiter
+}
+
 func MidRateLimit(handlerName string, settings interface{}) gin.HandlerFunc {
-	var total int
-	var duration time.Duration
-	var limitByIp bool
+	var keyStrategy string
+	headerMode := "ietf"
+	rules := make([]rateLimitRule, 0)
+	map1, _ := settings.(map[string]interface{})
 
-	if map1, ok := settings.(map[string]interface{}); ok && len(map1) > 0 {
-		total = castx.ToInt(map1["total"])
+	if s1, ok := settings.(string); ok && s1 != "" {
+		s1 = strings.ReplaceAll(s1, "[syh]", `"`)
+		map1 = jsonx.MapFrom(s1)
+	}
 
-		if d1, ok := map1["duration"].(time.Duration); ok && d1 > 0 {
-			duration = d1
-		} else if n1, err := castx.ToInt64E(map1["duration"]); err == nil && n1 > 0 {
-			duration = time.Duration(n1) * time.Millisecond
-		}
+	if len(map1) > 0 {
+		keyStrategy = castx.ToString(map1["key"])
 
-		limitByIp = castx.ToBool(map1["limitByIp"])
-	} else if s1, ok := settings.(string); ok && s1 != "" {
-		s1 = strings.ReplaceAll(s1, "[syh]", `"`)
-		map1 := jsonx.MapFrom(s1)
+		if s1 := strings.ToLower(castx.ToString(map1["headers"])); s1 != "" {
+			headerMode = s1
+		}
 
-		if len(map1) > 0 {
-			total = castx.ToInt(map1["total"])
+		if keyStrategy == "" && castx.ToBool(map1["limitByIp"]) {
+			keyStrategy = "ip"
+		}
 
-			if d1, ok := map1["duration"].(time.Duration); ok && d1 > 0 {
-				duration = d1
-			} else if n1, err := castx.ToInt64E(map1["duration"]); err == nil && n1 > 0 {
-				duration = time.Duration(n1) * time.Millisecond
+		if limits := castx.ToSlice(map1["limits"]); len(limits) > 0 {
+			for _, entry := range limits {
+				if rule, ok := parseRateLimitRule(castx.ToStringMap(entry), map1); ok {
+					rules = append(rules, rule)
+				}
 			}
+		} else if rule, ok := parseRateLimitRule(map1, nil); ok {
+			rule.suffix = ""
+			rules = append(rules, rule)
+		}
+	}
 
-			limitByIp = castx.ToBool(map1["limitByIp"])
+	policies := make([]string, 0)
+
+	for _, rule := range rules {
+		if rule.policy != "" {
+			policies = append(policies, rule.policy)
 		}
 	}
 
+	policy := strings.Join(policies, ", ")
+
 	return func(ctx *gin.Context) {
 		if AppConf.GetBoolean("logging.logMiddlewareRun") {
 			RuntimeLogger().Info("middleware run: mgboot.MidRateLimit")
 		}
 
-		if handlerName == "" || total < 1 || duration < 1 {
+		if handlerName == "" || len(rules) < 1 {
 			ctx.Next()
 			return
 		}
@@ -55,19 +73,113 @@ func MidRateLimit(handlerName string, settings interface{}) gin.HandlerFunc {
 		req := NewRequest(ctx)
 		id := handlerName
 
-		if limitByIp {
-			id += "@" + req.GetClientIp()
+		if key := resolveRateLimitKey(req, keyStrategy); key != "" {
+			id += "@" + key
 		}
 
-		opts := ratelimiter.NewRatelimiterOptions(RatelimiterLuaFile(), RatelimiterCacheDir())
-		limiter := ratelimiter.NewRatelimiter(id, total, duration, opts)
-		result := limiter.GetLimit()
-		remaining := castx.ToInt(result["remaining"])
+		var current, rejected *RateLimitResult
 
-		if remaining < 0 {
-			panic(NewRateLimitError(result))
+		for _, rule := range rules {
+			result := rule.limiter.Allow(id + rule.suffix)
+
+			if !result.Allowed {
+				rejected = &result
+				break
+			}
+
+			if current == nil || result.Remaining < current.Remaining {
+				current = &result
+			}
 		}
 
+		if rejected != nil {
+			data := rejected.toMap()
+			data["policy"] = policy
+			data["headerMode"] = headerMode
+			panic(NewRateLimitError(data))
+		}
+
+		addRateLimitHeaders(
+			ctx,
+			headerMode,
+			current.Limit,
+			current.Remaining,
+			toRateLimitSeconds(current.ResetAfter),
+			policy,
+		)
+
 		ctx.Next()
 	}
 }
+
+func parseRateLimitRule(map1 map[string]interface{}, parent map[string]interface{}) (rateLimitRule, bool) {
+	if l1, ok := map1["limiter"].(RateLimiter); ok {
+		return rateLimitRule{suffix: fmt.Sprintf("#%p", l1), limiter: l1}, true
+	}
+
+	total := castx.ToInt(map1["total"])
+	var duration time.Duration
+
+	if d1, ok := map1["duration"].(time.Duration); ok && d1 > 0 {
+		duration = d1
+	} else if s1, ok := map1["duration"].(string); ok && s1 != "" {
+		if d1, err := time.ParseDuration(s1); err == nil {
+			duration = d1
+		} else if n1, err := castx.ToInt64E(s1); err == nil && n1 > 0 {
+			duration = time.Duration(n1) * time.Millisecond
+		}
+	} else if n1, err := castx.ToInt64E(map1["duration"]); err == nil && n1 > 0 {
+		duration = time.Duration(n1) * time.Millisecond
+	}
+
+	if total < 1 || duration < 1 {
+		return rateLimitRule{}, false
+	}
+
+	algorithm := castx.ToString(map1["algorithm"])
+
+	if algorithm == "" && parent != nil {
+		algorithm = castx.ToString(parent["algorithm"])
+	}
+
+	return rateLimitRule{
+		suffix:  fmt.Sprintf("#%d/%s", total, duration),
+		policy:  fmt.Sprintf("%d;w=%d", total, toRateLimitSeconds(duration)),
+		limiter: NewRateLimiter(algorithm, total, duration),
+	}, true
+}
+
+func resolveRateLimitKey(req *Request, strategy string) string {
+	if strategy == "" {
+		return ""
+	}
+
+	if strategy == "ip" {
+		return req.GetClientIp()
+	}
+
+	var key string
+
+	switch {
+	case strategy == "subject":
+		key = req.GetAuthSubject()
+	case strings.HasPrefix(strategy, "jwt:"):
+		if tk := req.GetVerifiedJwt(); tk != nil {
+			key = JwtClaimString(tk, strategy[4:])
+		}
+	case strings.HasPrefix(strategy, "header:"):
+		key = req.GetHeader(strategy[7:])
+	case strings.HasPrefix(strategy, "query:"):
+		key = req.GetQueryParams()[strategy[6:]]
+	default:
+		if fn := GetRateLimitKeyFunc(strategy); fn != nil {
+			key = fn(req)
+		}
+	}
+
+	if key == "" {
+		return req.GetClientIp()
+	}
+
+	return strategy + "=" + key
+}
@@ -33,6 +33,12 @@ func MidRecover() gin.HandlerFunc {
 				return
 			}
 
+			// the timeout response has already been sent, the panic can only be logged
+			if IsRequestTimedOut(ctx) {
+				RuntimeLogger().Errorf("panic after request timeout: %s", errorx.Stacktrace(err))
+				return
+			}
+
 			handlers := ErrorHandlers()
 			var handler ErrorHandler
 
@@ -46,6 +52,7 @@ func MidRecover() gin.HandlerFunc {
 			LogExecuteTime(ctx)
 			AddPoweredBy(ctx)
 			AddCorsSupport(ctx)
+			AddSecurityHeaders(ctx)
 
 			if handler == nil {
 				RuntimeLogger().Error(errorx.Stacktrace(err))
@@ -57,6 +64,14 @@ func MidRecover() gin.HandlerFunc {
 				ex.AddSpecifyHeaders(ctx)
 			}
 
+			if ex, ok := err.(LoadSheddingError); ok {
+				ex.AddSpecifyHeaders(ctx)
+			}
+
+			if ex, ok := err.(PreconditionFailedError); ok {
+				ex.AddSpecifyHeaders(ctx)
+			}
+
 			payload := handler.HandleError(err)
 			statusCode, contents := payload.GetContents()
 
@@ -1,9 +1,7 @@
 package mgboot
 
 import (
-	"bytes"
 	"github.com/gin-gonic/gin"
-	"io/ioutil"
 	"strings"
 	"time"
 )
@@ -12,34 +10,47 @@ func MidRequestBody() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		ctx.Set("ExecStart", time.Now())
 		req := NewRequest(ctx)
-		method := req.GetMethod()
 
-		if method == "GET" {
+		if !isBodyMethod(req.GetMethod()) {
 			ctx.Next()
 			return
 		}
 
-		contentType := req.GetHeader("Content-Type")
-		isJsonPayload := strings.Contains(contentType, "json")
-		isXmlPayload := strings.Contains(contentType, "xml")
+		contentType := strings.ToLower(req.GetHeader("Content-Type"))
 
-		if !isJsonPayload && !isXmlPayload {
+		// tus chunks are limited by TusSettings.MaxChunkSize, the handler keeps the partial write
+		if strings.Contains(contentType, "application/offset+octet-stream") {
 			ctx.Next()
 			return
 		}
 
-		var buf []byte
+		if isBodyTooLarge(ctx) {
+			ctx.AbortWithStatus(413)
+			return
+		}
+
+		limitRequestBody(ctx)
 
-		if ctx.Request.Body != nil {
-			buf, _ = ctx.GetRawData()
+		if strings.Contains(contentType, "application/x-www-form-urlencoded") {
+			if ctx.Request.ParseForm(); isBodyLimitExceeded(ctx) {
+				ctx.AbortWithStatus(413)
+				return
+			}
+
+			ctx.Next()
+			return
 		}
 
-		if len(buf) < 1 {
-			buf = []byte{}
+		if !isDecodableContentType(req.GetHeader("Content-Type")) {
+			ctx.Next()
+			return
+		}
+
+		if _, err := readRawBody(ctx); err != nil {
+			ctx.AbortWithStatus(413)
+			return
 		}
 
-		ctx.Set("requestRawBody", buf)
-		ctx.Request.Body = ioutil.NopCloser(bytes.NewReader(buf))
 		ctx.Next()
 	}
 }
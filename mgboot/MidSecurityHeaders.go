@@ -0,0 +1,32 @@
+package mgboot
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+)
+
+func MidSecurityHeaders() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if AppConf.GetBoolean("logging.logMiddlewareRun") {
+			RuntimeLogger().Info("middleware run: mgboot.MidSecurityHeaders")
+		}
+
+		if GetSecurityHeadersSettings() != nil {
+			ensureCspNonce(ctx)
+		}
+
+		ctx.Next()
+	}
+}
+
+func ensureCspNonce(ctx *gin.Context) string {
+	if v1, ok := ctx.Get("CspNonce"); ok {
+		if s1, ok := v1.(string); ok && s1 != "" {
+			return s1
+		}
+	}
+
+	nonce := randomHexString(16)
+	ctx.Set("CspNonce", nonce)
+	return nonce
+}
@@ -0,0 +1,26 @@
+package mgboot
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+)
+
+func MidSession() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if AppConf.GetBoolean("logging.logMiddlewareRun") {
+			RuntimeLogger().Info("middleware run: mgboot.MidSession")
+		}
+
+		settings := GetSessionSettings()
+
+		if settings == nil {
+			ctx.Next()
+			return
+		}
+
+		sess := loadSession(ctx, settings)
+		ctx.Set("Session", sess)
+		defer sess.save()
+		ctx.Next()
+	}
+}
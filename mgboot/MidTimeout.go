@@ -0,0 +1,299 @@
+package mgboot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-common/util/jsonx"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// timeoutWriter buffers the response of the handlers running behind MidTimeout,
+// only the middleware goroutine copies it to the real writer, the same way
+// http.TimeoutHandler does
+type timeoutWriter struct {
+	gin.ResponseWriter
+	mu          sync.Mutex
+	header      http.Header
+	buf         bytes.Buffer
+	code        int
+	wroteHeader bool
+	timedOut    bool
+}
+
+func newTimeoutWriter(w gin.ResponseWriter) *timeoutWriter {
+	header := http.Header{}
+
+	for key, values := range w.Header() {
+		header[key] = append([]string{}, values...)
+	}
+
+	return &timeoutWriter{ResponseWriter: w, header: header, code: 200}
+}
+
+// Header must only be used by the handler goroutine, the map is handed over
+// to the middleware goroutine after the handlers have returned
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut || w.wroteHeader || code < 1 {
+		return
+	}
+
+	w.code = code
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut {
+		return
+	}
+
+	w.wroteHeader = true
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	w.wroteHeader = true
+	return w.buf.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush is a no-op, the response is buffered until the handlers return
+func (w *timeoutWriter) Flush() {
+}
+
+func (w *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errors.New("in mgboot.MidTimeout function, hijacking the connection is not supported")
+}
+
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.code
+}
+
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.wroteHeader {
+		return -1
+	}
+
+	return w.buf.Len()
+}
+
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.wroteHeader
+}
+
+func (w *timeoutWriter) isTimedOut() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.timedOut
+}
+
+func (w *timeoutWriter) markTimedOut() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timedOut = true
+}
+
+// flushTo copies the buffered response to the real writer, it must be called
+// after the handler goroutine has returned
+func (w *timeoutWriter) flushTo(dst gin.ResponseWriter) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	header := dst.Header()
+
+	for key := range header {
+		delete(header, key)
+	}
+
+	for key, values := range w.header {
+		header[key] = values
+	}
+
+	dst.WriteHeader(w.code)
+
+	if !w.wroteHeader {
+		return
+	}
+
+	dst.WriteHeaderNow()
+
+	if w.buf.Len() > 0 {
+		dst.Write(w.buf.Bytes())
+	}
+}
+
+func writeTimeoutResponse(w gin.ResponseWriter, err TimeoutError) {
+	var payload ResponsePayload
+
+	for _, h := range ErrorHandlers() {
+		if h.MatchError(err) {
+			payload = h.HandleError(err)
+			break
+		}
+	}
+
+	statusCode := err.StatusCode()
+	var contents string
+
+	if payload != nil {
+		code, s1 := payload.GetContents()
+
+		if code >= 400 {
+			statusCode = code
+		} else {
+			contents = s1
+		}
+	}
+
+	header := w.Header()
+	header.Set("Content-Length", fmt.Sprintf("%d", len(contents)))
+
+	if contents != "" && payload.GetContentType() != "" {
+		header.Set("Content-Type", payload.GetContentType())
+	}
+
+	if statusCode == 503 {
+		header.Set("Retry-After", "1")
+	}
+
+	w.WriteHeader(statusCode)
+	w.WriteHeaderNow()
+
+	if contents != "" {
+		w.WriteString(contents)
+	}
+
+	w.Flush()
+}
+
+// @param map[string]interface{}|string|time.Duration settings
+func MidTimeout(settings interface{}) gin.HandlerFunc {
+	var timeout time.Duration
+	var statusCode int
+	map1, _ := settings.(map[string]interface{})
+
+	if d1, ok := settings.(time.Duration); ok {
+		timeout = d1
+	} else if s1, ok := settings.(string); ok && s1 != "" {
+		s1 = strings.ReplaceAll(s1, "[syh]", `"`)
+
+		if d1, err := time.ParseDuration(s1); err == nil {
+			timeout = d1
+		} else {
+			map1 = jsonx.MapFrom(s1)
+		}
+	}
+
+	if len(map1) > 0 {
+		if d1, ok := map1["timeout"].(time.Duration); ok {
+			timeout = d1
+		} else if s1, ok := map1["timeout"].(string); ok {
+			timeout, _ = time.ParseDuration(s1)
+		} else if n1 := castx.ToInt64(map1["timeout"]); n1 > 0 {
+			timeout = time.Duration(n1) * time.Millisecond
+		}
+
+		statusCode = castx.ToInt(map1["statusCode"])
+	}
+
+	return func(ctx *gin.Context) {
+		if AppConf.GetBoolean("logging.logMiddlewareRun") {
+			RuntimeLogger().Info("middleware run: mgboot.MidTimeout")
+		}
+
+		if timeout < 1 {
+			ctx.Next()
+			return
+		}
+
+		c1, cancel := context.WithTimeout(ctx.Request.Context(), timeout)
+		defer cancel()
+		ctx.Request = ctx.Request.WithContext(c1)
+		w := ctx.Writer
+		tw := newTimeoutWriter(w)
+		ctx.Writer = tw
+		ctx.Set("TimeoutWriter", tw)
+		done := make(chan struct{})
+		var panicked interface{}
+
+		go func() {
+			defer func() {
+				panicked = recover()
+				close(done)
+			}()
+
+			ctx.Next()
+		}()
+
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		select {
+		case <-done:
+		case <-timer.C:
+			tw.markTimedOut()
+			writeTimeoutResponse(w, NewTimeoutError(timeout, statusCode))
+
+			// the gin context is recycled once this middleware returns, so wait for the
+			// handlers, which should give up as soon as the request context is done
+			<-done
+		}
+
+		ctx.Writer = w
+
+		if panicked != nil {
+			panic(panicked)
+		}
+
+		if tw.isTimedOut() {
+			ctx.Abort()
+			LogExecuteTime(ctx)
+			return
+		}
+
+		tw.flushTo(w)
+	}
+}
+
+func IsRequestTimedOut(ctx *gin.Context) bool {
+	v1, _ := ctx.Get("TimeoutWriter")
+
+	if tw, ok := v1.(*timeoutWriter); ok {
+		return tw.isTimedOut()
+	}
+
+	return false
+}
@@ -0,0 +1,62 @@
+package mgboot
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMidTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	WithBuiltinErrorHandlers()
+	router := gin.New()
+	router.Use(MidRecover(), MidTimeout(50*time.Millisecond))
+
+	router.GET("/fast", func(ctx *gin.Context) {
+		ctx.Header("X-Test", "fast")
+		ctx.String(201, "ok")
+	})
+
+	router.GET("/slow", func(ctx *gin.Context) {
+		<-ctx.Request.Context().Done()
+		ctx.Header("X-Test", "slow")
+		ctx.String(200, "late")
+	})
+
+	router.GET("/panic", func(ctx *gin.Context) {
+		panic(NewAuthorizationError("denied"))
+	})
+
+	router.GET("/late-panic", func(ctx *gin.Context) {
+		<-ctx.Request.Context().Done()
+		panic("boom")
+	})
+
+	cases := []struct {
+		path   string
+		code   int
+		body   string
+		header string
+	}{
+		{"/fast", 201, "ok", "fast"},
+		{"/slow", 504, "", ""},
+		{"/late-panic", 504, "", ""},
+	}
+
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", c.path, nil))
+
+		if w.Code != c.code || w.Body.String() != c.body || w.Header().Get("X-Test") != c.header {
+			t.Errorf("%s: expected %d %q X-Test=%q, got %d %q X-Test=%q", c.path, c.code, c.body, c.header, w.Code, w.Body.String(), w.Header().Get("X-Test"))
+		}
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+
+	if w.Code != 403 {
+		t.Errorf("/panic: expected the panic to reach MidRecover, got %d %q", w.Code, w.Body.String())
+	}
+}
@@ -57,7 +57,7 @@ func MidValidate(arg0 interface{}) gin.HandlerFunc {
 
 		validator := validatex.NewValidator()
 		req := NewRequest(ctx)
-		data := req.GetMap()
+		data := legacyParamMap(req, req.GetMap())
 
 		if failfast {
 			errorTips := validatex.FailfastValidate(validator, data, rules)
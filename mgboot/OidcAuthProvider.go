@@ -0,0 +1,59 @@
+package mgboot
+
+import (
+	"github.com/dgrijalva/jwt-go"
+	"github.com/meiguonet/mgboot-go-common/enum/RegexConst"
+	"github.com/meiguonet/mgboot-go-common/util/stringx"
+	"github.com/meiguonet/mgboot-go-gin/enum/JwtVerifyErrno"
+	"strings"
+)
+
+type oidcAuthProvider struct {
+	settingsKey string
+}
+
+func NewOidcAuthProvider(settingsKey string) *oidcAuthProvider {
+	return &oidcAuthProvider{settingsKey: settingsKey}
+}
+
+func (p *oidcAuthProvider) GetSchemeName() string {
+	return "oidc"
+}
+
+func (p *oidcAuthProvider) Supports(req *Request) bool {
+	s1 := strings.TrimSpace(req.GetHeader("Authorization"))
+	return strings.ToLower(stringx.SubstringBefore(s1, " ")) == "bearer"
+}
+
+func (p *oidcAuthProvider) Authenticate(req *Request) (string, error) {
+	provider := GetOidcProvider(p.settingsKey)
+
+	if provider == nil {
+		return "", NewAuthenticationError(p.GetSchemeName(), "oidc provider not configured")
+	}
+
+	token := strings.TrimSpace(req.GetHeader("Authorization"))
+	token = stringx.RegexReplace(token, RegexConst.SpaceSep, " ")
+
+	if strings.Contains(token, " ") {
+		token = stringx.SubstringAfter(token, " ")
+	}
+
+	if token == "" {
+		return "", NewJwtAuthError(JwtVerifyErrno.NotFound)
+	}
+
+	tk, err := provider.ValidateToken(token)
+
+	if err != nil {
+		if ve, ok := err.(*jwt.ValidationError); ok && ve.Errors&jwt.ValidationErrorExpired != 0 {
+			return "", NewJwtAuthError(JwtVerifyErrno.Expired)
+		}
+
+		RuntimeLogger().Debugf("oidc token rejected: %s", err.Error())
+		return "", NewJwtAuthError(JwtVerifyErrno.Invalid)
+	}
+
+	req.ctx.Set("JwtToken", tk)
+	return JwtClaimString(tk, "sub"), nil
+}
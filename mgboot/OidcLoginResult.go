@@ -0,0 +1,12 @@
+package mgboot
+
+import "github.com/dgrijalva/jwt-go"
+
+type OidcLoginResult struct {
+	AccessToken  string
+	RefreshToken string
+	IdToken      string
+	ExpiresIn    int64
+	ReturnTo     string
+	Claims       jwt.MapClaims
+}
@@ -0,0 +1,462 @@
+package mgboot
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/go-errors/errors"
+	ccachex "github.com/meiguonet/mgboot-go-common/cachex"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-gin/cachex"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type OidcProviderMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+type oidcProvider struct {
+	settings          *OidcSettings
+	mu                sync.RWMutex
+	metadata          *OidcProviderMetadata
+	metadataFetchedAt time.Time
+	keys              map[string]*rsa.PublicKey
+	keysFetchedAt     time.Time
+}
+
+func NewOidcProvider(settings *OidcSettings) *oidcProvider {
+	return &oidcProvider{
+		settings: settings,
+		keys:     map[string]*rsa.PublicKey{},
+	}
+}
+
+func (p *oidcProvider) Settings() *OidcSettings {
+	return p.settings
+}
+
+func (p *oidcProvider) Metadata() (*OidcProviderMetadata, error) {
+	p.mu.RLock()
+	metadata := p.metadata
+	fetchedAt := p.metadataFetchedAt
+	p.mu.RUnlock()
+
+	if metadata != nil && !p.isExpired(fetchedAt) {
+		return metadata, nil
+	}
+
+	fresh, err := p.fetchMetadata()
+
+	if err != nil {
+		if metadata != nil {
+			RuntimeLogger().Warnf("fail to refresh oidc provider metadata, using cached copy: %s", err.Error())
+			return metadata, nil
+		}
+
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.metadata = fresh
+	p.metadataFetchedAt = time.Now()
+	p.mu.Unlock()
+	return fresh, nil
+}
+
+func (p *oidcProvider) fetchMetadata() (*OidcProviderMetadata, error) {
+	if p.settings.Issuer() == "" {
+		return nil, errors.New("in mgboot.oidcProvider, issuer is empty")
+	}
+
+	buf, err := p.httpGet(p.settings.Issuer() + "/.well-known/openid-configuration")
+
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &OidcProviderMetadata{}
+
+	if err := json.Unmarshal(buf, metadata); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimRight(metadata.Issuer, "/") != p.settings.Issuer() {
+		return nil, fmt.Errorf("in mgboot.oidcProvider, issuer mismatch: %s", metadata.Issuer)
+	}
+
+	return metadata, nil
+}
+
+func (p *oidcProvider) isExpired(fetchedAt time.Time) bool {
+	ttl := p.settings.MetadataTtl()
+	return ttl > 0 && time.Now().Sub(fetchedAt) >= ttl
+}
+
+func (p *oidcProvider) ValidateToken(token string, audience ...string) (*jwt.Token, error) {
+	metadata, err := p.Metadata()
+
+	if err != nil {
+		return nil, err
+	}
+
+	tk, err := jwt.Parse(token, func(tk *jwt.Token) (interface{}, error) {
+		if _, ok := tk.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", tk.Header["alg"])
+		}
+
+		return p.getKey(castx.ToString(tk.Header["kid"]))
+	})
+
+	if err != nil {
+		return tk, err
+	}
+
+	claims, ok := tk.Claims.(jwt.MapClaims)
+
+	if !ok {
+		return nil, errors.New("unexpected claims type")
+	}
+
+	if strings.TrimRight(castx.ToString(claims["iss"]), "/") != strings.TrimRight(metadata.Issuer, "/") {
+		return nil, errors.New("issuer mismatch")
+	}
+
+	aud := p.settings.Audience()
+
+	if len(audience) > 0 && audience[0] != "" {
+		aud = audience[0]
+	}
+
+	if aud != "" && !policyValueIn(aud, toAuthorizeStringSlice(claims["aud"])) {
+		return nil, errors.New("audience mismatch")
+	}
+
+	tk.Claims = p.mapClaims(claims)
+	return tk, nil
+}
+
+func (p *oidcProvider) AuthCodeUrl(returnTo ...string) (string, error) {
+	metadata, err := p.Metadata()
+
+	if err != nil {
+		return "", err
+	}
+
+	state := randomHexString(16)
+	nonce := randomHexString(16)
+	codeVerifier := base64.RawURLEncoding.EncodeToString([]byte(randomHexString(32)))
+	var _returnTo string
+
+	if len(returnTo) > 0 {
+		_returnTo = returnTo[0]
+	}
+
+	entry := map[string]interface{}{
+		"nonce":        nonce,
+		"codeVerifier": codeVerifier,
+		"returnTo":     _returnTo,
+	}
+
+	if !p.stateStore().Set("oidc.state."+state, entry, p.settings.StateTtl()) {
+		return "", errors.New("in mgboot.oidcProvider, fail to save login state")
+	}
+
+	digest := sha256.Sum256([]byte(codeVerifier))
+	values := url.Values{}
+	values.Set("response_type", "code")
+	values.Set("client_id", p.settings.ClientId())
+	values.Set("redirect_uri", p.settings.RedirectUrl())
+	values.Set("scope", strings.Join(p.settings.Scopes(), " "))
+	values.Set("state", state)
+	values.Set("nonce", nonce)
+	values.Set("code_challenge", base64.RawURLEncoding.EncodeToString(digest[:]))
+	values.Set("code_challenge_method", "S256")
+
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		return metadata.AuthorizationEndpoint + "&" + values.Encode(), nil
+	}
+
+	return metadata.AuthorizationEndpoint + "?" + values.Encode(), nil
+}
+
+func (p *oidcProvider) Exchange(state, code string) (*OidcLoginResult, error) {
+	if state == "" || code == "" {
+		return nil, errors.New("in mgboot.oidcProvider, state or code is empty")
+	}
+
+	store := p.stateStore()
+	entry := castx.ToStringMap(store.Get("oidc.state." + state))
+	store.Delete("oidc.state." + state)
+
+	if len(entry) < 1 {
+		return nil, errors.New("in mgboot.oidcProvider, invalid or expired state")
+	}
+
+	metadata, err := p.Metadata()
+
+	if err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	values.Set("grant_type", "authorization_code")
+	values.Set("code", code)
+	values.Set("redirect_uri", p.settings.RedirectUrl())
+	values.Set("client_id", p.settings.ClientId())
+	values.Set("code_verifier", castx.ToString(entry["codeVerifier"]))
+
+	if p.settings.ClientSecret() != "" {
+		values.Set("client_secret", p.settings.ClientSecret())
+	}
+
+	client := &http.Client{Timeout: p.settings.HttpTimeout()}
+	resp, err := client.PostForm(metadata.TokenEndpoint, values)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	buf, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("in mgboot.oidcProvider, token endpoint returns http error %d: %s", resp.StatusCode, string(buf))
+	}
+
+	var payload struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		IdToken      string `json:"id_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+	}
+
+	if err := json.Unmarshal(buf, &payload); err != nil {
+		return nil, err
+	}
+
+	if payload.IdToken == "" {
+		return nil, errors.New("in mgboot.oidcProvider, id_token not found in token response")
+	}
+
+	tk, err := p.ValidateToken(payload.IdToken, p.settings.ClientId())
+
+	if err != nil {
+		return nil, err
+	}
+
+	claims := tk.Claims.(jwt.MapClaims)
+
+	if castx.ToString(claims["nonce"]) != castx.ToString(entry["nonce"]) {
+		return nil, errors.New("in mgboot.oidcProvider, nonce mismatch")
+	}
+
+	return &OidcLoginResult{
+		AccessToken:  payload.AccessToken,
+		RefreshToken: payload.RefreshToken,
+		IdToken:      payload.IdToken,
+		ExpiresIn:    payload.ExpiresIn,
+		ReturnTo:     castx.ToString(entry["returnTo"]),
+		Claims:       claims,
+	}, nil
+}
+
+func (p *oidcProvider) getKey(kid string) (*rsa.PublicKey, error) {
+	p.mu.RLock()
+	key := p.findKey(kid)
+	fetchedAt := p.keysFetchedAt
+	p.mu.RUnlock()
+
+	if key != nil && !p.isExpired(fetchedAt) {
+		return key, nil
+	}
+
+	if key != nil {
+		if err := p.refreshKeys(); err != nil {
+			RuntimeLogger().Warnf("fail to refresh oidc signing keys, using cached copy: %s", err.Error())
+			return key, nil
+		}
+
+		p.mu.RLock()
+		key = p.findKey(kid)
+		p.mu.RUnlock()
+
+		if key == nil {
+			return nil, fmt.Errorf("signing key not found: %s", kid)
+		}
+
+		return key, nil
+	}
+
+	if !fetchedAt.IsZero() && time.Now().Sub(fetchedAt) < 30*time.Second {
+		return nil, fmt.Errorf("signing key not found: %s", kid)
+	}
+
+	if err := p.refreshKeys(); err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	key = p.findKey(kid)
+	p.mu.RUnlock()
+
+	if key == nil {
+		return nil, fmt.Errorf("signing key not found: %s", kid)
+	}
+
+	return key, nil
+}
+
+func (p *oidcProvider) findKey(kid string) *rsa.PublicKey {
+	if kid != "" {
+		return p.keys[kid]
+	}
+
+	if len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key
+		}
+	}
+
+	return nil
+}
+
+func (p *oidcProvider) refreshKeys() error {
+	metadata, err := p.Metadata()
+
+	if err != nil {
+		return err
+	}
+
+	buf, err := p.httpGet(metadata.JwksUri)
+
+	if err != nil {
+		return err
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+
+	if err := json.Unmarshal(buf, &jwks); err != nil {
+		return err
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+
+	for _, entry := range jwks.Keys {
+		if entry.Kty != "RSA" || (entry.Use != "" && entry.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(entry.N)
+
+		if err != nil {
+			continue
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(entry.E)
+
+		if err != nil || len(e) < 1 {
+			continue
+		}
+
+		keys[entry.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *oidcProvider) mapClaims(claims jwt.MapClaims) jwt.MapClaims {
+	mapping := p.settings.ClaimMapping()
+
+	if len(mapping) < 1 {
+		return claims
+	}
+
+	mapped := jwt.MapClaims{}
+
+	for name, value := range claims {
+		mapped[name] = value
+	}
+
+	for name, path := range mapping {
+		var value interface{} = map[string]interface{}(claims)
+
+		for _, part := range strings.Split(path, ".") {
+			value = castx.ToStringMap(value)[part]
+
+			if value == nil {
+				break
+			}
+		}
+
+		if value != nil {
+			mapped[name] = value
+		}
+	}
+
+	return mapped
+}
+
+func (p *oidcProvider) stateStore() ccachex.ICache {
+	name := p.settings.StateStore()
+
+	if name == "" {
+		name = cachex.DefaultStore()
+	}
+
+	return cachex.Store(name)
+}
+
+func (p *oidcProvider) httpGet(requestUrl string) ([]byte, error) {
+	client := &http.Client{Timeout: p.settings.HttpTimeout()}
+	resp, err := client.Get(requestUrl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	buf, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("in mgboot.oidcProvider, fail to fetch %s, http error %d", requestUrl, resp.StatusCode)
+	}
+
+	return buf, nil
+}
@@ -0,0 +1,332 @@
+package mgboot
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"github.com/meiguonet/mgboot-go-gin/cachex"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeIdpCode struct {
+	challenge string
+	nonce     string
+}
+
+type fakeIdp struct {
+	t         *testing.T
+	server    *httptest.Server
+	mu        sync.Mutex
+	key       *rsa.PrivateKey
+	kid       string
+	tokenPath string
+	codes     map[string]fakeIdpCode
+}
+
+func newFakeIdp(t *testing.T) *fakeIdp {
+	idp := &fakeIdp{t: t, tokenPath: "/token", codes: map[string]fakeIdpCode{}}
+	idp.rotateKey("k1")
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		idp.mu.Lock()
+		tokenPath := idp.tokenPath
+		idp.mu.Unlock()
+
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + tokenPath,
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		idp.mu.Lock()
+		defer idp.mu.Unlock()
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": idp.kid,
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(idp.key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(idp.key.E)).Bytes()),
+			}},
+		})
+	})
+
+	tokenHandler := func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		idp.mu.Lock()
+		entry, ok := idp.codes[r.PostForm.Get("code")]
+		delete(idp.codes, r.PostForm.Get("code"))
+		idp.mu.Unlock()
+
+		if !ok {
+			http.Error(w, `{"error":"invalid_grant"}`, 400)
+			return
+		}
+
+		digest := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+
+		if base64.RawURLEncoding.EncodeToString(digest[:]) != entry.challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, 400)
+			return
+		}
+
+		idToken := idp.sign(jwt.MapClaims{"sub": "alice", "aud": "client1", "nonce": entry.nonce})
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "at", "id_token": idToken, "expires_in": 3600})
+	}
+
+	mux.HandleFunc("/token", tokenHandler)
+	mux.HandleFunc("/token2", tokenHandler)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *fakeIdp) rotateKey(kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+
+	idp.mu.Lock()
+	idp.key = key
+	idp.kid = kid
+	idp.mu.Unlock()
+}
+
+func (idp *fakeIdp) sign(claims jwt.MapClaims) string {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	if _, ok := claims["iss"]; !ok {
+		claims["iss"] = idp.server.URL
+	}
+
+	claims["exp"] = time.Now().Add(time.Hour).Unix()
+	tk := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tk.Header["kid"] = idp.kid
+	s1, err := tk.SignedString(idp.key)
+
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+
+	return s1
+}
+
+func newTestOidcProvider(idp *fakeIdp) *oidcProvider {
+	cachex.WithMemoryCache(time.Minute, time.Minute)
+
+	return NewOidcProvider(NewOidcSettings(map[string]interface{}{
+		"issuer":      idp.server.URL,
+		"clientId":    "client1",
+		"audience":    "client1",
+		"redirectUrl": "http://localhost/callback",
+		"stateStore":  "memory",
+	}))
+}
+
+func TestOidcProviderDiscovery(t *testing.T) {
+	idp := newFakeIdp(t)
+	p := newTestOidcProvider(idp)
+	metadata, err := p.Metadata()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if metadata.TokenEndpoint != idp.server.URL+"/token" || metadata.JwksUri != idp.server.URL+"/jwks" {
+		t.Fatalf("unexpected metadata: %+v", metadata)
+	}
+
+	bad := NewOidcProvider(NewOidcSettings(map[string]interface{}{"issuer": idp.server.URL + "/other"}))
+
+	if _, err := bad.Metadata(); err == nil {
+		t.Fatal("expected discovery to fail for an unknown issuer")
+	}
+}
+
+func TestOidcProviderMetadataTtl(t *testing.T) {
+	idp := newFakeIdp(t)
+	p := newTestOidcProvider(idp)
+
+	if _, err := p.Metadata(); err != nil {
+		t.Fatal(err)
+	}
+
+	idp.mu.Lock()
+	idp.tokenPath = "/token2"
+	idp.mu.Unlock()
+	metadata, _ := p.Metadata()
+
+	if metadata.TokenEndpoint != idp.server.URL+"/token" {
+		t.Fatal("metadata should be served from cache before the ttl expires")
+	}
+
+	p.mu.Lock()
+	p.metadataFetchedAt = time.Now().Add(-2 * time.Hour)
+	p.mu.Unlock()
+	metadata, _ = p.Metadata()
+
+	if metadata.TokenEndpoint != idp.server.URL+"/token2" {
+		t.Fatalf("metadata should be refreshed after the ttl expires, got %s", metadata.TokenEndpoint)
+	}
+}
+
+func TestOidcProviderJwksRotation(t *testing.T) {
+	idp := newFakeIdp(t)
+	p := newTestOidcProvider(idp)
+
+	if _, err := p.ValidateToken(idp.sign(jwt.MapClaims{"sub": "alice", "aud": "client1"})); err != nil {
+		t.Fatal(err)
+	}
+
+	idp.rotateKey("k2")
+	token := idp.sign(jwt.MapClaims{"sub": "alice", "aud": "client1"})
+
+	if _, err := p.ValidateToken(token); err == nil {
+		t.Fatal("unknown kid should not trigger a refetch within the throttle window")
+	}
+
+	p.mu.Lock()
+	p.keysFetchedAt = time.Now().Add(-time.Minute)
+	p.mu.Unlock()
+
+	if _, err := p.ValidateToken(token); err != nil {
+		t.Fatalf("rotated key should be picked up: %s", err)
+	}
+}
+
+func TestOidcProviderRejectsBadIssuerAndAudience(t *testing.T) {
+	idp := newFakeIdp(t)
+	p := newTestOidcProvider(idp)
+
+	cases := []struct {
+		name   string
+		claims jwt.MapClaims
+	}{
+		{"bad iss", jwt.MapClaims{"sub": "alice", "aud": "client1", "iss": "https://evil.example.com"}},
+		{"bad aud", jwt.MapClaims{"sub": "alice", "aud": "someone-else"}},
+		{"missing aud", jwt.MapClaims{"sub": "alice"}},
+	}
+
+	for _, c := range cases {
+		if _, err := p.ValidateToken(idp.sign(c.claims)); err == nil {
+			t.Errorf("%s: expected token to be rejected", c.name)
+		}
+	}
+}
+
+func TestOidcProviderPkceRoundTrip(t *testing.T) {
+	idp := newFakeIdp(t)
+	p := newTestOidcProvider(idp)
+	authUrl, err := p.AuthCodeUrl("/back")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u1, _ := url.Parse(authUrl)
+	query := u1.Query()
+
+	if query.Get("code_challenge_method") != "S256" || query.Get("state") == "" || query.Get("nonce") == "" {
+		t.Fatalf("unexpected auth url: %s", authUrl)
+	}
+
+	idp.mu.Lock()
+	idp.codes["c1"] = fakeIdpCode{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	idp.mu.Unlock()
+	result, err := p.Exchange(query.Get("state"), "c1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.ReturnTo != "/back" || result.Claims["sub"] != "alice" {
+		t.Fatalf("unexpected login result: %+v", result)
+	}
+
+	if _, err := p.Exchange(query.Get("state"), "c1"); err == nil {
+		t.Fatal("state must not be reusable")
+	}
+
+	authUrl, _ = p.AuthCodeUrl()
+	u1, _ = url.Parse(authUrl)
+	query = u1.Query()
+	idp.mu.Lock()
+	idp.codes["c2"] = fakeIdpCode{challenge: query.Get("code_challenge"), nonce: "forged"}
+	idp.mu.Unlock()
+
+	if _, err := p.Exchange(query.Get("state"), "c2"); err == nil {
+		t.Fatal("expected nonce mismatch to be rejected")
+	}
+
+	authUrl, _ = p.AuthCodeUrl()
+	u1, _ = url.Parse(authUrl)
+	query = u1.Query()
+	idp.mu.Lock()
+	idp.codes["c3"] = fakeIdpCode{challenge: "wrong-challenge", nonce: query.Get("nonce")}
+	idp.mu.Unlock()
+
+	if _, err := p.Exchange(query.Get("state"), "c3"); err == nil {
+		t.Fatal("expected pkce verifier mismatch to be rejected")
+	}
+}
+
+func TestMidOidcAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	WithBuiltinErrorHandlers()
+	idp := newFakeIdp(t)
+	oidcProviders["test"] = newTestOidcProvider(idp)
+	defer delete(oidcProviders, "test")
+
+	router := gin.New()
+	router.Use(MidRecover(), MidOidcAuth("test"))
+
+	router.GET("/", func(ctx *gin.Context) {
+		ctx.String(200, NewRequest(ctx).GetAuthSubject())
+	})
+
+	router.GET("/missing", MidOidcAuth("nope"), func(ctx *gin.Context) {
+		ctx.String(200, "ok")
+	})
+
+	cases := []struct {
+		path    string
+		token   string
+		code    int
+		reached bool
+	}{
+		{"/", idp.sign(jwt.MapClaims{"sub": "alice", "aud": "client1"}), 200, true},
+		{"/", idp.sign(jwt.MapClaims{"sub": "alice", "aud": "client1", "iss": "https://evil.example.com"}), 200, false},
+		{"/missing", idp.sign(jwt.MapClaims{"sub": "alice", "aud": "client1"}), 401, false},
+	}
+
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", c.path, nil)
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		router.ServeHTTP(w, req)
+
+		reached := w.Body.String() == "alice" || w.Body.String() == "ok"
+
+		if w.Code != c.code || reached != c.reached {
+			t.Errorf("%s: expected %d reached=%v, got %d %q", c.path, c.code, c.reached, w.Code, w.Body.String())
+		}
+	}
+}
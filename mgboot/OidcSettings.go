@@ -0,0 +1,111 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"strings"
+	"time"
+)
+
+type OidcSettings struct {
+	issuer       string
+	clientId     string
+	clientSecret string
+	redirectUrl  string
+	scopes       []string
+	audience     string
+	claimMapping map[string]string
+	stateStore   string
+	stateTtl     time.Duration
+	httpTimeout  time.Duration
+	metadataTtl  time.Duration
+}
+
+func NewOidcSettings(settings map[string]interface{}) *OidcSettings {
+	scopes := []string{"openid", "profile", "email"}
+
+	if a1 := toAuthorizeStringSlice(settings["scopes"]); len(a1) > 0 {
+		scopes = a1
+	}
+
+	stateTtl := 10 * time.Minute
+
+	if d1, ok := settings["stateTtl"].(time.Duration); ok && d1 > 0 {
+		stateTtl = d1
+	} else if s1, ok := settings["stateTtl"].(string); ok && s1 != "" {
+		stateTtl = castx.ToDuration(s1)
+	}
+
+	httpTimeout := 10 * time.Second
+
+	if d1, ok := settings["httpTimeout"].(time.Duration); ok && d1 > 0 {
+		httpTimeout = d1
+	} else if s1, ok := settings["httpTimeout"].(string); ok && s1 != "" {
+		httpTimeout = castx.ToDuration(s1)
+	}
+
+	metadataTtl := time.Hour
+
+	if d1, ok := settings["metadataTtl"].(time.Duration); ok && d1 > 0 {
+		metadataTtl = d1
+	} else if s1, ok := settings["metadataTtl"].(string); ok && s1 != "" {
+		metadataTtl = castx.ToDuration(s1)
+	}
+
+	return &OidcSettings{
+		issuer:       strings.TrimRight(castx.ToString(settings["issuer"]), "/"),
+		clientId:     castx.ToString(settings["clientId"]),
+		clientSecret: castx.ToString(settings["clientSecret"]),
+		redirectUrl:  castx.ToString(settings["redirectUrl"]),
+		scopes:       scopes,
+		audience:     castx.ToString(settings["audience"]),
+		claimMapping: castx.ToStringMapString(settings["claimMapping"]),
+		stateStore:   castx.ToString(settings["stateStore"]),
+		stateTtl:     stateTtl,
+		httpTimeout:  httpTimeout,
+		metadataTtl:  metadataTtl,
+	}
+}
+
+func (st *OidcSettings) Issuer() string {
+	return st.issuer
+}
+
+func (st *OidcSettings) ClientId() string {
+	return st.clientId
+}
+
+func (st *OidcSettings) ClientSecret() string {
+	return st.clientSecret
+}
+
+func (st *OidcSettings) RedirectUrl() string {
+	return st.redirectUrl
+}
+
+func (st *OidcSettings) Scopes() []string {
+	return st.scopes
+}
+
+func (st *OidcSettings) Audience() string {
+	return st.audience
+}
+
+func (st *OidcSettings) ClaimMapping() map[string]string {
+	return st.claimMapping
+}
+
+func (st *OidcSettings) StateStore() string {
+	return st.stateStore
+}
+
+func (st *OidcSettings) StateTtl() time.Duration {
+	return st.stateTtl
+}
+
+func (st *OidcSettings) HttpTimeout() time.Duration {
+	return st.httpTimeout
+}
+
+func (st *OidcSettings) MetadataTtl() time.Duration {
+	return st.metadataTtl
+}
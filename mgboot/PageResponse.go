@@ -0,0 +1,154 @@
+package mgboot
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/meiguonet/mgboot-go-common/util/jsonx"
+	"net/url"
+	"strings"
+)
+
+type PageResponse struct {
+	items      interface{}
+	total      int64
+	page       int
+	size       int
+	cursor     string
+	nextCursor string
+	requestUrl *url.URL
+}
+
+func NewPageResponse(req *Request, pageable *Pageable, items interface{}, total int64) PageResponse {
+	if items == nil {
+		items = make([]interface{}, 0)
+	}
+
+	if pageable == nil && req != nil {
+		pageable = req.Pageable()
+	} else if pageable == nil {
+		pageable = &Pageable{Page: 1, Size: 20}
+	}
+
+	var requestUrl *url.URL
+
+	if req != nil && req.ctx.Request != nil && req.ctx.Request.URL != nil {
+		u1 := *req.ctx.Request.URL
+		requestUrl = &u1
+	}
+
+	return PageResponse{
+		items:      items,
+		total:      total,
+		page:       pageable.Page,
+		size:       pageable.Size,
+		cursor:     pageable.Cursor,
+		requestUrl: requestUrl,
+	}
+}
+
+func (p PageResponse) WithNextCursor(cursor string) PageResponse {
+	p.nextCursor = cursor
+	return p
+}
+
+func (p PageResponse) GetContentType() string {
+	return "application/json; charset=utf-8"
+}
+
+func (p PageResponse) GetContents() (int, string) {
+	payload := map[string]interface{}{
+		"items":      p.items,
+		"total":      p.total,
+		"page":       p.page,
+		"size":       p.size,
+		"totalPages": p.TotalPages(),
+		"hasNext":    p.HasNext(),
+	}
+
+	if p.cursor != "" || p.nextCursor != "" {
+		payload["nextCursor"] = p.nextCursor
+	}
+
+	opts := jsonx.NewToJsonOption().HandleTimeField().StripZeroTimePart()
+	return 200, jsonx.ToJson(payload, opts)
+}
+
+func (p PageResponse) TotalPages() int {
+	if p.size < 1 || p.total < 1 {
+		return 0
+	}
+
+	return int((p.total + int64(p.size) - 1) / int64(p.size))
+}
+
+func (p PageResponse) HasNext() bool {
+	if p.cursor != "" || p.nextCursor != "" {
+		return p.nextCursor != ""
+	}
+
+	return p.page < p.TotalPages()
+}
+
+func (p PageResponse) AddSpecifyHeaders(ctx *gin.Context) {
+	ctx.Header("X-Total-Count", fmt.Sprintf("%d", p.total))
+
+	if links := p.buildLinks(); len(links) > 0 {
+		ctx.Header("Link", strings.Join(links, ", "))
+	}
+}
+
+func (p PageResponse) buildLinks() []string {
+	links := make([]string, 0)
+
+	if p.requestUrl == nil {
+		return links
+	}
+
+	if p.cursor != "" || p.nextCursor != "" {
+		if p.nextCursor != "" {
+			links = append(links, p.buildLink("next", map[string]string{"cursor": p.nextCursor}))
+		}
+
+		return links
+	}
+
+	totalPages := p.TotalPages()
+
+	if totalPages < 1 {
+		return links
+	}
+
+	links = append(links, p.buildLink("first", map[string]string{"page": "1"}))
+
+	if p.page > 1 {
+		prev := p.page - 1
+
+		if prev > totalPages {
+			prev = totalPages
+		}
+
+		links = append(links, p.buildLink("prev", map[string]string{"page": fmt.Sprintf("%d", prev)}))
+	}
+
+	if p.page < totalPages {
+		links = append(links, p.buildLink("next", map[string]string{"page": fmt.Sprintf("%d", p.page+1)}))
+	}
+
+	links = append(links, p.buildLink("last", map[string]string{"page": fmt.Sprintf("%d", totalPages)}))
+	return links
+}
+
+func (p PageResponse) buildLink(rel string, params map[string]string) string {
+	u1 := *p.requestUrl
+	query := u1.Query()
+	query.Del("offset")
+	query.Del("pageSize")
+	query.Set("size", fmt.Sprintf("%d", p.size))
+
+	for key, value := range params {
+		query.Set(key, value)
+	}
+
+	u1.RawQuery = query.Encode()
+	return fmt.Sprintf(`<%s>; rel="%s"`, u1.RequestURI(), rel)
+}
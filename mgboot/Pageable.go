@@ -0,0 +1,226 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type SortOrder struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
+
+type FilterCondition struct {
+	Field    string   `json:"field"`
+	Operator string   `json:"operator"`
+	Value    string   `json:"value"`
+	Values   []string `json:"values,omitempty"`
+}
+
+type Pageable struct {
+	Page    int               `json:"page"`
+	Size    int               `json:"size"`
+	Offset  int               `json:"offset"`
+	Cursor  string            `json:"cursor,omitempty"`
+	Sort    []SortOrder       `json:"sort"`
+	Filters []FilterCondition `json:"filters"`
+}
+
+var pageableFieldRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+var pageableFilterKeyRegex = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_.]*)\[([a-z]+)]$`)
+
+var pageableFilterOperators = []string{"eq", "ne", "gt", "gte", "lt", "lte", "like", "in", "nin", "null"}
+
+func NewPageable(req *Request, settings ...map[string]interface{}) *Pageable {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	defaultSize := castx.ToInt(_settings["defaultSize"])
+
+	if defaultSize < 1 {
+		defaultSize = 20
+	}
+
+	maxSize := castx.ToInt(_settings["maxSize"])
+
+	if maxSize < 1 {
+		maxSize = 100
+	}
+
+	sortFields := castx.ToStringSlice(_settings["sortFields"])
+	filterFields := castx.ToStringSlice(_settings["filterFields"])
+	query := req.GetQueryValues()
+
+	size := castx.ToInt(query.Get("size"), -1)
+
+	if size < 1 {
+		size = castx.ToInt(query.Get("pageSize"), defaultSize)
+	}
+
+	if size < 1 {
+		size = defaultSize
+	}
+
+	if size > maxSize {
+		size = maxSize
+	}
+
+	page := castx.ToInt(query.Get("page"), 1)
+
+	if page < 1 {
+		page = 1
+	}
+
+	offset := (page - 1) * size
+
+	if s1 := query.Get("offset"); s1 != "" {
+		if n1 := castx.ToInt(s1, -1); n1 >= 0 {
+			offset = n1
+			page = n1/size + 1
+		}
+	}
+
+	orders := parseSortOrders(query.Get("sort"), sortFields)
+
+	if len(orders) < 1 {
+		// defaultSort comes from the server settings, so it does not need to be listed in sortFields
+		defaultSort := castx.ToString(_settings["defaultSort"])
+		defaultFields := strings.Split(strings.NewReplacer("-", "", "+", "", " ", "").Replace(defaultSort), ",")
+		orders = parseSortOrders(defaultSort, defaultFields)
+	}
+
+	return &Pageable{
+		Page:    page,
+		Size:    size,
+		Offset:  offset,
+		Cursor:  strings.TrimSpace(query.Get("cursor")),
+		Sort:    orders,
+		Filters: parseFilterConditions(query, filterFields),
+	}
+}
+
+func (p *Pageable) Limit() int {
+	return p.Size
+}
+
+func (p *Pageable) IsCursorMode() bool {
+	return p.Cursor != ""
+}
+
+func (p *Pageable) GetFilter(field string) *FilterCondition {
+	for idx := range p.Filters {
+		if p.Filters[idx].Field == field {
+			return &p.Filters[idx]
+		}
+	}
+
+	return nil
+}
+
+func (p *Pageable) SortString() string {
+	parts := make([]string, 0, len(p.Sort))
+
+	for _, order := range p.Sort {
+		if order.Desc {
+			parts = append(parts, "-"+order.Field)
+		} else {
+			parts = append(parts, order.Field)
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func parseSortOrders(expr string, allowedFields []string) []SortOrder {
+	orders := make([]SortOrder, 0)
+	seen := map[string]bool{}
+
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		var desc bool
+
+		if strings.HasPrefix(part, "-") {
+			desc = true
+			part = part[1:]
+		} else if strings.HasPrefix(part, "+") {
+			part = part[1:]
+		}
+
+		if part == "" || seen[part] || !isPageableFieldAllowed(part, allowedFields) {
+			continue
+		}
+
+		seen[part] = true
+		orders = append(orders, SortOrder{Field: part, Desc: desc})
+	}
+
+	return orders
+}
+
+func parseFilterConditions(query map[string][]string, allowedFields []string) []FilterCondition {
+	keys := make([]string, 0, len(query))
+
+	for key := range query {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	filters := make([]FilterCondition, 0)
+
+	for _, key := range keys {
+		values := query[key]
+
+		if len(values) < 1 {
+			continue
+		}
+
+		var field, op string
+
+		if matches := pageableFilterKeyRegex.FindStringSubmatch(key); len(matches) == 3 {
+			field, op = matches[1], matches[2]
+		} else if len(allowedFields) > 0 && policyValueIn(key, allowedFields) {
+			field, op = key, "eq"
+		} else {
+			continue
+		}
+
+		if !policyValueIn(op, pageableFilterOperators) || !isPageableFieldAllowed(field, allowedFields) {
+			continue
+		}
+
+		filter := FilterCondition{Field: field, Operator: op, Value: values[0]}
+
+		if op == "in" || op == "nin" {
+			items := make([]string, 0)
+
+			for _, value := range values {
+				for _, item := range strings.Split(value, ",") {
+					if item = strings.TrimSpace(item); item != "" {
+						items = append(items, item)
+					}
+				}
+			}
+
+			filter.Values = items
+			filter.Value = strings.Join(items, ",")
+		}
+
+		filters = append(filters, filter)
+	}
+
+	return filters
+}
+
+func isPageableFieldAllowed(field string, allowedFields []string) bool {
+	if !pageableFieldRegex.MatchString(field) {
+		return false
+	}
+
+	// sorting and filtering are opt-in per field, nothing is allowed without sortFields/filterFields
+	return len(allowedFields) > 0 && policyValueIn(field, allowedFields)
+}
@@ -0,0 +1,115 @@
+package mgboot
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func newPageableTestRequest(target string) *Request {
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("GET", target, nil)
+	return NewRequest(ctx)
+}
+
+func TestPageableSort(t *testing.T) {
+	settings := map[string]interface{}{"sortFields": []string{"a", "b"}, "defaultSort": "-id"}
+
+	cases := []struct {
+		target   string
+		settings map[string]interface{}
+		expected string
+	}{
+		{"/?sort=-a,b", settings, "-a,b"},
+		{"/?sort=+b,-a,b", settings, "b,-a"},
+		{"/?sort=-a,c,a%3Bdrop", settings, "-a"},
+		{"/?sort=c", settings, "-id"},
+		{"/", settings, "-id"},
+		{"/?sort=-a,b", nil, ""},
+	}
+
+	for _, c := range cases {
+		if s1 := NewPageable(newPageableTestRequest(c.target), c.settings).SortString(); s1 != c.expected {
+			t.Errorf("%s: expected sort %q, got %q", c.target, c.expected, s1)
+		}
+	}
+}
+
+func TestPageableFilters(t *testing.T) {
+	settings := map[string]interface{}{"filterFields": []string{"price", "status", "id"}}
+	pageable := NewPageable(newPageableTestRequest("/?price[gte]=10&status=1&name[like]=x&price[bad]=1&id[in]=1,2&id[in]=3"), settings)
+
+	expected := []FilterCondition{
+		{Field: "id", Operator: "in", Value: "1,2,3", Values: []string{"1", "2", "3"}},
+		{Field: "price", Operator: "gte", Value: "10"},
+		{Field: "status", Operator: "eq", Value: "1"},
+	}
+
+	if !reflect.DeepEqual(pageable.Filters, expected) {
+		t.Fatalf("unexpected filters: %+v", pageable.Filters)
+	}
+
+	if f := pageable.GetFilter("price"); f == nil || f.Operator != "gte" {
+		t.Fatalf("unexpected price filter: %+v", f)
+	}
+
+	if filters := NewPageable(newPageableTestRequest("/?price[gte]=10&status=1")).Filters; len(filters) != 0 {
+		t.Fatalf("filters must be denied without filterFields, got %+v", filters)
+	}
+}
+
+func TestPageablePaging(t *testing.T) {
+	cases := []struct {
+		target string
+		page   int
+		size   int
+		offset int
+	}{
+		{"/", 1, 20, 0},
+		{"/?page=3&size=10", 3, 10, 20},
+		{"/?page=2&pageSize=15", 2, 15, 15},
+		{"/?offset=45&size=20", 3, 20, 45},
+		{"/?offset=40&size=20", 3, 20, 40},
+		{"/?offset=-1&page=2", 2, 20, 20},
+		{"/?size=1000", 1, 100, 0},
+		{"/?page=0&size=0", 1, 20, 0},
+	}
+
+	for _, c := range cases {
+		p := NewPageable(newPageableTestRequest(c.target))
+
+		if p.Page != c.page || p.Size != c.size || p.Offset != c.offset {
+			t.Errorf("%s: expected page=%d size=%d offset=%d, got %d %d %d", c.target, c.page, c.size, c.offset, p.Page, p.Size, p.Offset)
+		}
+	}
+}
+
+func TestPageResponseLinks(t *testing.T) {
+	req := newPageableTestRequest("/items?page=2&size=10&offset=10&sort=-a")
+	resp := NewPageResponse(req, nil, []int{1, 2}, 35)
+
+	if resp.TotalPages() != 4 || !resp.HasNext() {
+		t.Fatalf("unexpected paging: totalPages=%d hasNext=%v", resp.TotalPages(), resp.HasNext())
+	}
+
+	links := resp.buildLinks()
+
+	expected := []string{
+		`</items?page=1&size=10&sort=-a>; rel="first"`,
+		`</items?page=1&size=10&sort=-a>; rel="prev"`,
+		`</items?page=3&size=10&sort=-a>; rel="next"`,
+		`</items?page=4&size=10&sort=-a>; rel="last"`,
+	}
+
+	if !reflect.DeepEqual(links, expected) {
+		t.Fatalf("unexpected links: %v", links)
+	}
+
+	resp = NewPageResponse(newPageableTestRequest("/items?cursor=abc"), nil, nil, 0).WithNextCursor("def")
+
+	if !resp.HasNext() || !reflect.DeepEqual(resp.buildLinks(), []string{`</items?cursor=def&size=20>; rel="next"`}) {
+		t.Fatalf("unexpected cursor links: %v", resp.buildLinks())
+	}
+}
@@ -0,0 +1,257 @@
+package mgboot
+
+import (
+	"github.com/dgrijalva/jwt-go"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"strings"
+)
+
+type Policy struct {
+	id         string
+	effect     string
+	subjects   map[string][]string
+	actions    []string
+	resources  []string
+	conditions []policyCondition
+}
+
+type policyCondition struct {
+	left  string
+	op    string
+	right string
+}
+
+func NewPolicy(settings map[string]interface{}) *Policy {
+	effect := strings.ToLower(castx.ToString(settings["effect"]))
+
+	if effect != "deny" {
+		effect = "allow"
+	}
+
+	subjects := map[string][]string{}
+
+	for claimName, expected := range castx.ToStringMap(settings["subjects"]) {
+		if s1, ok := expected.(string); ok {
+			subjects[claimName] = []string{s1}
+			continue
+		}
+
+		subjects[claimName] = castx.ToStringSlice(expected)
+	}
+
+	conditions := make([]policyCondition, 0)
+
+	for _, entry := range castx.ToSlice(settings["conditions"]) {
+		if s1, ok := entry.(string); ok {
+			parts := strings.Fields(s1)
+
+			if len(parts) == 3 {
+				conditions = append(conditions, policyCondition{left: parts[0], op: parts[1], right: parts[2]})
+			}
+
+			continue
+		}
+
+		map1 := castx.ToStringMap(entry)
+
+		if len(map1) < 1 {
+			continue
+		}
+
+		conditions = append(conditions, policyCondition{
+			left:  castx.ToString(map1["left"]),
+			op:    castx.ToString(map1["op"]),
+			right: castx.ToString(map1["right"]),
+		})
+	}
+
+	return &Policy{
+		id:         castx.ToString(settings["id"]),
+		effect:     effect,
+		subjects:   subjects,
+		actions:    toAuthorizeStringSlice(settings["actions"]),
+		resources:  toAuthorizeStringSlice(settings["resources"]),
+		conditions: conditions,
+	}
+}
+
+func (p *Policy) Id() string {
+	return p.id
+}
+
+func (p *Policy) IsDeny() bool {
+	return p.effect == "deny"
+}
+
+func (p *Policy) Match(req *Request, claims jwt.MapClaims, action, resource string) bool {
+	if !p.matchAction(action) {
+		return false
+	}
+
+	resourceVars, ok := p.matchResource(resource)
+
+	if !ok {
+		return false
+	}
+
+	for claimName, expected := range p.subjects {
+		var matched bool
+
+		for _, value := range toAuthorizeStringSlice(claims[claimName]) {
+			if policyValueIn(value, expected) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	for _, cond := range p.conditions {
+		left := resolvePolicyOperand(cond.left, req, claims, resourceVars)
+		right := resolvePolicyOperand(cond.right, req, claims, resourceVars)
+
+		if !comparePolicyOperands(left, cond.op, right) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *Policy) matchAction(action string) bool {
+	for _, pattern := range p.actions {
+		if pattern == "*" || pattern == action {
+			return true
+		}
+
+		if strings.HasSuffix(pattern, "*") && strings.HasPrefix(action, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Policy) matchResource(resource string) (map[string]string, bool) {
+	for _, pattern := range p.resources {
+		if vars, ok := matchPolicyResource(pattern, resource); ok {
+			return vars, true
+		}
+	}
+
+	return nil, false
+}
+
+func matchPolicyResource(pattern, resource string) (map[string]string, bool) {
+	vars := map[string]string{}
+
+	if pattern == "*" || pattern == "**" {
+		return vars, true
+	}
+
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	resourceParts := strings.Split(strings.Trim(resource, "/"), "/")
+
+	for idx, part := range patternParts {
+		if part == "**" {
+			return vars, true
+		}
+
+		if idx >= len(resourceParts) {
+			return nil, false
+		}
+
+		switch {
+		case part == "*":
+		case strings.HasPrefix(part, ":"):
+			vars[part[1:]] = resourceParts[idx]
+		case strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}"):
+			vars[part[1:len(part)-1]] = resourceParts[idx]
+		case part != resourceParts[idx]:
+			return nil, false
+		}
+	}
+
+	if len(patternParts) != len(resourceParts) {
+		return nil, false
+	}
+
+	return vars, true
+}
+
+func resolvePolicyOperand(operand string, req *Request, claims jwt.MapClaims, resourceVars map[string]string) []string {
+	idx := strings.Index(operand, ".")
+
+	if idx < 1 {
+		return []string{operand}
+	}
+
+	name := operand[idx+1:]
+
+	switch operand[:idx] {
+	case "claim":
+		return toAuthorizeStringSlice(claims[name])
+	case "resource":
+		return []string{resourceVars[name]}
+	case "path":
+		if req == nil {
+			return []string{}
+		}
+
+		return []string{req.PathvariableString(name)}
+	case "query":
+		if req == nil {
+			return []string{}
+		}
+
+		return []string{req.GetQueryParams()[name]}
+	case "header":
+		if req == nil {
+			return []string{}
+		}
+
+		return []string{req.GetHeader(name)}
+	}
+
+	return []string{operand}
+}
+
+func comparePolicyOperands(left []string, op string, right []string) bool {
+	if len(left) < 1 || len(right) < 1 {
+		return op == "!=" || op == "ne"
+	}
+
+	switch op {
+	case "==", "eq":
+		return left[0] != "" && left[0] == right[0]
+	case "!=", "ne":
+		return left[0] != right[0]
+	case "in":
+		return policyValueIn(left[0], right)
+	case "contains":
+		return policyValueIn(right[0], left)
+	case ">", "gt":
+		return castx.ToFloat64(left[0]) > castx.ToFloat64(right[0])
+	case ">=", "gte":
+		return castx.ToFloat64(left[0]) >= castx.ToFloat64(right[0])
+	case "<", "lt":
+		return castx.ToFloat64(left[0]) < castx.ToFloat64(right[0])
+	case "<=", "lte":
+		return castx.ToFloat64(left[0]) <= castx.ToFloat64(right[0])
+	}
+
+	return false
+}
+
+func policyValueIn(value string, values []string) bool {
+	for _, s1 := range values {
+		if s1 == "*" || s1 == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,19 @@
+package mgboot
+
+type PolicyDecision struct {
+	allowed  bool
+	policyId string
+	reason   string
+}
+
+func (d PolicyDecision) Allowed() bool {
+	return d.allowed
+}
+
+func (d PolicyDecision) PolicyId() string {
+	return d.policyId
+}
+
+func (d PolicyDecision) Reason() string {
+	return d.reason
+}
@@ -0,0 +1,31 @@
+package mgboot
+
+import "github.com/gin-gonic/gin"
+
+type PreconditionFailedError struct {
+	etag string
+}
+
+func NewPreconditionFailedError(etag ...string) PreconditionFailedError {
+	var _etag string
+
+	if len(etag) > 0 {
+		_etag = etag[0]
+	}
+
+	return PreconditionFailedError{etag: _etag}
+}
+
+func (ex PreconditionFailedError) Error() string {
+	return "precondition failed"
+}
+
+func (ex PreconditionFailedError) Etag() string {
+	return ex.etag
+}
+
+func (ex PreconditionFailedError) AddSpecifyHeaders(ctx *gin.Context) {
+	if ex.etag != "" {
+		ctx.Header("ETag", ex.etag)
+	}
+}
@@ -0,0 +1,24 @@
+package mgboot
+
+type preconditionFailedErrorHandler struct {
+}
+
+func NewPreconditionFailedErrorHandler() *preconditionFailedErrorHandler {
+	return &preconditionFailedErrorHandler{}
+}
+
+func (h *preconditionFailedErrorHandler) GetErrorName() string {
+	return "builtin.PreconditionFailedError"
+}
+
+func (h *preconditionFailedErrorHandler) MatchError(err error) bool {
+	if _, ok := err.(PreconditionFailedError); ok {
+		return true
+	}
+
+	return false
+}
+
+func (h *preconditionFailedErrorHandler) HandleError(_ error) ResponsePayload {
+	return NewHttpErrorResponse(412)
+}
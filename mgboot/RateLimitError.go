@@ -3,37 +3,34 @@ package mgboot
 import (
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"math"
+	"time"
 )
 
 type RateLimitError struct {
 	total      int
 	remaining  int
-	retryAfter string
+	reset      int
+	retryAfter int
+	policy     string
+	headerMode string
 }
 
 func NewRateLimitError(data map[string]interface{}) RateLimitError {
-	var total int
-
-	if n1, ok := data["total"].(int); ok && n1 > 0 {
-		total = n1
-	}
-
 	var remaining int
 
-	if n1, ok := data["remaining"].(int); ok && n1 > 0 {
+	if n1 := castx.ToInt(data["remaining"]); n1 > 0 {
 		remaining = n1
 	}
 
-	var retryAfter string
-
-	if s1, ok := data["retryAfter"].(string); ok && s1 != "" {
-		retryAfter = s1
-	}
-
 	return RateLimitError{
-		total:      total,
+		total:      castx.ToInt(data["total"]),
 		remaining:  remaining,
-		retryAfter: retryAfter,
+		reset:      toRateLimitSeconds(data["reset"]),
+		retryAfter: toRateLimitSeconds(data["retryAfter"]),
+		policy:     castx.ToString(data["policy"]),
+		headerMode: castx.ToString(data["headerMode"]),
 	}
 }
 
@@ -49,15 +46,93 @@ func (ex RateLimitError) Remaining() int {
 	return ex.remaining
 }
 
+func (ex RateLimitError) Reset() int {
+	return ex.reset
+}
+
 func (ex RateLimitError) RetryAfter() string {
+	if ex.retryAfter < 1 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d", ex.retryAfter)
+}
+
+func (ex RateLimitError) RetryAfterSeconds() int {
 	return ex.retryAfter
 }
 
+func (ex RateLimitError) Policy() string {
+	return ex.policy
+}
+
 func (ex RateLimitError) AddSpecifyHeaders(ctx *gin.Context) {
-	ctx.Header("X-Ratelimit-Limit", fmt.Sprintf("%d", ex.Total()))
-	ctx.Header("X-Ratelimit-Remaining", fmt.Sprintf("%d", ex.Remaining()))
+	addRateLimitHeaders(ctx, ex.headerMode, ex.total, ex.remaining, ex.reset, ex.policy)
 
 	if ex.RetryAfter() != "" {
 		ctx.Header("Retry-After", ex.RetryAfter())
 	}
 }
+
+func addRateLimitHeaders(ctx *gin.Context, mode string, total, remaining, reset int, policy string) {
+	if mode == "none" {
+		return
+	}
+
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	if mode != "legacy" {
+		ctx.Header("RateLimit-Limit", fmt.Sprintf("%d", total))
+		ctx.Header("RateLimit-Remaining", fmt.Sprintf("%d", remaining))
+		ctx.Header("RateLimit-Reset", fmt.Sprintf("%d", reset))
+
+		if policy != "" {
+			ctx.Header("RateLimit-Policy", policy)
+		}
+	}
+
+	if mode == "legacy" || mode == "both" {
+		ctx.Header("X-RateLimit-Limit", fmt.Sprintf("%d", total))
+		ctx.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
+		ctx.Header("X-RateLimit-Reset", fmt.Sprintf("%d", reset))
+	}
+}
+
+func toRateLimitSeconds(arg0 interface{}) int {
+	switch v := arg0.(type) {
+	case time.Duration:
+		if v < 1 {
+			return 0
+		}
+
+		return int(math.Ceil(v.Seconds()))
+	case time.Time:
+		return toRateLimitSeconds(v.Sub(time.Now()))
+	case string:
+		if v == "" {
+			return 0
+		}
+
+		if n1, err := castx.ToIntE(v); err == nil {
+			return toRateLimitSeconds(n1)
+		}
+
+		if t1, err := time.ParseInLocation("2006-01-02 15:04:05", v, time.Local); err == nil {
+			return toRateLimitSeconds(t1)
+		}
+
+		if t1, err := time.Parse(time.RFC1123, v); err == nil {
+			return toRateLimitSeconds(t1)
+		}
+
+		return 0
+	}
+
+	if n1 := castx.ToInt(arg0); n1 > 0 {
+		return n1
+	}
+
+	return 0
+}
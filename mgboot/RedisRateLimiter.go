@@ -0,0 +1,72 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-dal/ratelimiter"
+	"time"
+)
+
+type redisRateLimiter struct {
+	total    int
+	duration time.Duration
+}
+
+func NewRedisRateLimiter(total int, duration time.Duration) *redisRateLimiter {
+	return &redisRateLimiter{total: total, duration: duration}
+}
+
+func (l *redisRateLimiter) GetAlgorithmName() string {
+	return "redis"
+}
+
+func (l *redisRateLimiter) Allow(key string) RateLimitResult {
+	opts := ratelimiter.NewRatelimiterOptions(RatelimiterLuaFile(), RatelimiterCacheDir())
+	limiter := ratelimiter.NewRatelimiter(key, l.total, l.duration, opts)
+	data := limiter.GetLimit()
+	remaining := castx.ToInt(data["remaining"])
+	result := RateLimitResult{Limit: l.total, Allowed: remaining >= 0}
+
+	if remaining > 0 {
+		result.Remaining = remaining
+	}
+
+	result.RetryAfter = parseRedisRateLimitDuration(data["retryAfter"])
+
+	for _, name := range []string{"resetAfter", "reset", "ttl"} {
+		if d1 := parseRedisRateLimitDuration(data[name]); d1 > 0 {
+			result.ResetAfter = d1
+			break
+		}
+	}
+
+	// the redis window expires with its key, so when the script does not report
+	// the ttl, a rejected request resets when it may be retried and an allowed one
+	// no later than one full window from now
+	if result.ResetAfter <= 0 && !result.Allowed {
+		result.ResetAfter = result.RetryAfter
+	}
+
+	if result.ResetAfter <= 0 {
+		result.ResetAfter = l.duration
+	}
+
+	return result
+}
+
+func parseRedisRateLimitDuration(arg0 interface{}) time.Duration {
+	s1 := castx.ToString(arg0)
+
+	if s1 == "" {
+		return 0
+	}
+
+	if n1, err := castx.ToInt64E(s1); err == nil && n1 > 0 {
+		return time.Duration(n1) * time.Second
+	}
+
+	if t1, err := time.ParseInLocation("2006-01-02 15:04:05", s1, time.Local); err == nil {
+		return t1.Sub(rateLimiterNow())
+	}
+
+	return 0
+}
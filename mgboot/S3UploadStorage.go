@@ -0,0 +1,211 @@
+package mgboot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type s3UploadStorage struct {
+	endpoint  string
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	baseUrl   string
+	client    *http.Client
+}
+
+func NewS3UploadStorage(settings map[string]interface{}) *s3UploadStorage {
+	region := castx.ToString(settings["region"])
+
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	timeout := 30 * time.Second
+
+	if d1, ok := settings["timeout"].(time.Duration); ok && d1 > 0 {
+		timeout = d1
+	}
+
+	return &s3UploadStorage{
+		endpoint:  strings.TrimRight(castx.ToString(settings["endpoint"]), "/"),
+		region:    region,
+		bucket:    castx.ToString(settings["bucket"]),
+		accessKey: castx.ToString(settings["accessKey"]),
+		secretKey: castx.ToString(settings["secretKey"]),
+		baseUrl:   strings.TrimRight(castx.ToString(settings["baseUrl"]), "/"),
+		client:    &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *s3UploadStorage) Save(key string, reader io.Reader, size int64, contentType string) error {
+	req, err := s.newRequest("PUT", key, reader)
+
+	if err != nil {
+		return err
+	}
+
+	req.ContentLength = size
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req)
+
+	if err != nil {
+		return err
+	}
+
+	resp.Body.Close()
+	return nil
+}
+
+func (s *s3UploadStorage) Exists(key string) bool {
+	req, err := s.newRequest("HEAD", key, nil)
+
+	if err != nil {
+		return false
+	}
+
+	resp, err := s.do(req)
+
+	if err != nil {
+		return false
+	}
+
+	resp.Body.Close()
+	return true
+}
+
+func (s *s3UploadStorage) Open(key string) (io.ReadCloser, error) {
+	req, err := s.newRequest("GET", key, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+func (s *s3UploadStorage) Delete(key string) error {
+	req, err := s.newRequest("DELETE", key, nil)
+
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req)
+
+	if err != nil {
+		return err
+	}
+
+	resp.Body.Close()
+	return nil
+}
+
+func (s *s3UploadStorage) Url(key string) string {
+	if s.baseUrl != "" {
+		return s.baseUrl + "/" + strings.TrimLeft(key, "/")
+	}
+
+	return s.endpoint + s.objectPath(key)
+}
+
+func (s *s3UploadStorage) objectPath(key string) string {
+	parts := strings.Split(strings.TrimLeft(key, "/"), "/")
+
+	for idx, part := range parts {
+		parts[idx] = url.PathEscape(part)
+	}
+
+	return "/" + url.PathEscape(s.bucket) + "/" + strings.Join(parts, "/")
+}
+
+func (s *s3UploadStorage) newRequest(method, key string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, s.endpoint+s.objectPath(key), body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.sign(req, time.Now().UTC())
+	return req, nil
+}
+
+func (s *s3UploadStorage) do(req *http.Request) (*http.Response, error) {
+	resp, err := s.client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		buf, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("in mgboot.s3UploadStorage, http error %d: %s", resp.StatusCode, string(buf))
+	}
+
+	return resp, nil
+}
+
+func (s *s3UploadStorage) sign(req *http.Request, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+	payloadHash := "UNSIGNED-PAYLOAD"
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := dateStamp + "/" + s.region + "/s3/aws4_request"
+	digest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(digest[:])
+	signingKey := s3HmacSha256([]byte("AWS4"+s.secretKey), dateStamp)
+	signingKey = s3HmacSha256(signingKey, s.region)
+	signingKey = s3HmacSha256(signingKey, "s3")
+	signingKey = s3HmacSha256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(s3HmacSha256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey,
+		scope,
+		signedHeaders,
+		signature,
+	))
+}
+
+func s3HmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,105 @@
+package mgboot
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// s3StandInHandler serves the subset of the s3 object api used by s3UploadStorage
+// from a local directory
+
+type s3StandInHandler struct {
+	storage *localUploadStorage
+}
+
+func newS3StandInHandler(dir string) http.Handler {
+	return &s3StandInHandler{storage: NewLocalUploadStorage(dir)}
+}
+
+func (h *s3StandInHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	key := strings.TrimLeft(r.URL.Path, "/")
+
+	if key == "" || !strings.Contains(key, "/") {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case "PUT":
+		if err := h.storage.Save(key, r.Body, r.ContentLength, r.Header.Get("Content-Type")); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case "HEAD":
+		if !h.storage.Exists(key) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	case "GET":
+		fd, err := h.storage.Open(key)
+
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		defer fd.Close()
+		w.WriteHeader(http.StatusOK)
+		io.Copy(w, fd)
+	case "DELETE":
+		h.storage.Delete(key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3UploadStorage(t *testing.T) {
+	server := httptest.NewServer(newS3StandInHandler(t.TempDir()))
+	defer server.Close()
+
+	storage := NewS3UploadStorage(map[string]interface{}{
+		"endpoint":  server.URL,
+		"bucket":    "uploads",
+		"accessKey": "ak",
+		"secretKey": "sk",
+	})
+
+	if err := storage.Save("a/b.txt", strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !storage.Exists("a/b.txt") || storage.Exists("a/missing.txt") {
+		t.Fatal("unexpected Exists result")
+	}
+
+	fd, err := storage.Open("a/b.txt")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf, _ := ioutil.ReadAll(fd)
+	fd.Close()
+
+	if string(buf) != "hello" {
+		t.Fatalf("unexpected contents: %q", string(buf))
+	}
+
+	if err := storage.Delete("a/b.txt"); err != nil || storage.Exists("a/b.txt") {
+		t.Fatalf("object should be deleted, err: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"time"
+)
+
+type SecurityHeadersSettings struct {
+	hstsMaxAge            time.Duration
+	hstsIncludeSubDomains bool
+	hstsPreload           bool
+	contentSecurityPolicy string
+	contentTypeOptions    string
+	frameOptions          string
+	referrerPolicy        string
+	permissionsPolicy     string
+}
+
+func NewSecurityHeadersSettings(settings map[string]interface{}) *SecurityHeadersSettings {
+	hstsMaxAge := 180 * 24 * time.Hour
+
+	if d1, ok := settings["hstsMaxAge"].(time.Duration); ok && d1 >= 0 {
+		hstsMaxAge = d1
+	} else if s1, ok := settings["hstsMaxAge"].(string); ok && s1 != "" {
+		hstsMaxAge = castx.ToDuration(s1)
+	}
+
+	contentTypeOptions := "nosniff"
+
+	if s1, ok := settings["contentTypeOptions"].(string); ok {
+		contentTypeOptions = s1
+	}
+
+	frameOptions := "DENY"
+
+	if s1, ok := settings["frameOptions"].(string); ok {
+		frameOptions = s1
+	}
+
+	referrerPolicy := "strict-origin-when-cross-origin"
+
+	if s1, ok := settings["referrerPolicy"].(string); ok {
+		referrerPolicy = s1
+	}
+
+	return &SecurityHeadersSettings{
+		hstsMaxAge:            hstsMaxAge,
+		hstsIncludeSubDomains: castx.ToBool(settings["hstsIncludeSubDomains"]),
+		hstsPreload:           castx.ToBool(settings["hstsPreload"]),
+		contentSecurityPolicy: castx.ToString(settings["contentSecurityPolicy"]),
+		contentTypeOptions:    contentTypeOptions,
+		frameOptions:          frameOptions,
+		referrerPolicy:        referrerPolicy,
+		permissionsPolicy:     castx.ToString(settings["permissionsPolicy"]),
+	}
+}
+
+func (st *SecurityHeadersSettings) HstsMaxAge() time.Duration {
+	return st.hstsMaxAge
+}
+
+func (st *SecurityHeadersSettings) HstsIncludeSubDomains() bool {
+	return st.hstsIncludeSubDomains
+}
+
+func (st *SecurityHeadersSettings) HstsPreload() bool {
+	return st.hstsPreload
+}
+
+func (st *SecurityHeadersSettings) ContentSecurityPolicy() string {
+	return st.contentSecurityPolicy
+}
+
+func (st *SecurityHeadersSettings) ContentTypeOptions() string {
+	return st.contentTypeOptions
+}
+
+func (st *SecurityHeadersSettings) FrameOptions() string {
+	return st.frameOptions
+}
+
+func (st *SecurityHeadersSettings) ReferrerPolicy() string {
+	return st.referrerPolicy
+}
+
+func (st *SecurityHeadersSettings) PermissionsPolicy() string {
+	return st.permissionsPolicy
+}
@@ -0,0 +1,325 @@
+package mgboot
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"github.com/gin-gonic/gin"
+	ccachex "github.com/meiguonet/mgboot-go-common/cachex"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-common/util/jsonx"
+	"github.com/meiguonet/mgboot-go-gin/cachex"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+type Session struct {
+	mu        sync.RWMutex
+	ctx       *gin.Context
+	settings  *SessionSettings
+	id        string
+	oldId     string
+	data      map[string]interface{}
+	isNew     bool
+	destroyed bool
+}
+
+func loadSession(ctx *gin.Context, settings *SessionSettings) *Session {
+	sess := &Session{ctx: ctx, settings: settings, data: map[string]interface{}{}}
+	cookieValue, _ := ctx.Cookie(settings.CookieName())
+	id := decodeSessionId(cookieValue, settings)
+
+	if id != "" {
+		if contents := castx.ToString(sessionStore(settings).Get("session." + id)); contents != "" {
+			sess.id = id
+			sess.data = jsonx.MapFrom(contents)
+
+			if sess.data == nil {
+				sess.data = map[string]interface{}{}
+			}
+		}
+	}
+
+	if sess.id == "" {
+		sess.id = randomHexString(32)
+		sess.isNew = true
+	}
+
+	sess.writeCookie()
+	return sess
+}
+
+func (s *Session) Id() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.id
+}
+
+func (s *Session) IsNew() bool {
+	return s.isNew
+}
+
+func (s *Session) Get(key string, defaultValue ...interface{}) interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if value, ok := s.data[key]; ok {
+		return value
+	}
+
+	if len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+
+	return nil
+}
+
+func (s *Session) Set(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+}
+
+func (s *Session) Has(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.data[key]
+	return ok
+}
+
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+}
+
+func (s *Session) All() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	map1 := map[string]interface{}{}
+
+	for key, value := range s.data {
+		if key == "_flash" {
+			continue
+		}
+
+		map1[key] = value
+	}
+
+	return map1
+}
+
+func (s *Session) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = map[string]interface{}{}
+}
+
+func (s *Session) Flash(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	flashes := castx.ToStringMap(s.data["_flash"])
+
+	if flashes == nil {
+		flashes = map[string]interface{}{}
+	}
+
+	flashes[key] = append(castx.ToSlice(flashes[key]), value)
+	s.data["_flash"] = flashes
+}
+
+func (s *Session) GetFlash(key string) []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	flashes := castx.ToStringMap(s.data["_flash"])
+	values := castx.ToSlice(flashes[key])
+
+	if len(flashes) > 0 {
+		delete(flashes, key)
+		s.data["_flash"] = flashes
+	}
+
+	if values == nil {
+		return make([]interface{}, 0)
+	}
+
+	return values
+}
+
+func (s *Session) Regenerate() {
+	s.mu.Lock()
+
+	if s.oldId == "" && !s.isNew {
+		s.oldId = s.id
+	}
+
+	s.id = randomHexString(32)
+	s.mu.Unlock()
+	s.writeCookie()
+}
+
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	s.destroyed = true
+	s.data = map[string]interface{}{}
+	s.mu.Unlock()
+	removeSetCookieHeader(s.ctx, s.settings.CookieName())
+
+	http.SetCookie(s.ctx.Writer, &http.Cookie{
+		Name:     s.settings.CookieName(),
+		Value:    "",
+		MaxAge:   -1,
+		Path:     s.settings.CookiePath(),
+		Domain:   s.settings.CookieDomain(),
+		Secure:   s.settings.Secure(),
+		HttpOnly: true,
+		SameSite: s.settings.SameSite(),
+	})
+}
+
+func (s *Session) save() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	store := sessionStore(s.settings)
+
+	if s.oldId != "" {
+		store.Delete("session." + s.oldId)
+	}
+
+	if s.destroyed {
+		store.Delete("session." + s.id)
+		return
+	}
+
+	if s.isNew && len(s.data) < 1 {
+		return
+	}
+
+	store.Set("session."+s.id, jsonx.ToJson(s.data), s.settings.Ttl())
+}
+
+func (s *Session) writeCookie() {
+	removeSetCookieHeader(s.ctx, s.settings.CookieName())
+
+	http.SetCookie(s.ctx.Writer, &http.Cookie{
+		Name:     s.settings.CookieName(),
+		Value:    encodeSessionId(s.Id(), s.settings),
+		MaxAge:   int(s.settings.Ttl().Seconds()),
+		Path:     s.settings.CookiePath(),
+		Domain:   s.settings.CookieDomain(),
+		Secure:   s.settings.Secure(),
+		HttpOnly: true,
+		SameSite: s.settings.SameSite(),
+	})
+}
+
+func removeSetCookieHeader(ctx *gin.Context, name string) {
+	header := ctx.Writer.Header()
+	values := make([]string, 0)
+
+	for _, value := range header.Values("Set-Cookie") {
+		if strings.HasPrefix(value, name+"=") {
+			continue
+		}
+
+		values = append(values, value)
+	}
+
+	header.Del("Set-Cookie")
+
+	for _, value := range values {
+		header.Add("Set-Cookie", value)
+	}
+}
+
+func sessionStore(settings *SessionSettings) ccachex.ICache {
+	if settings.Store() != "" {
+		return cachex.Store(settings.Store())
+	}
+
+	return cachex.Store(cachex.DefaultStore())
+}
+
+func encodeSessionId(id string, settings *SessionSettings) string {
+	key := sha256.Sum256([]byte(settings.Secret()))
+
+	if settings.Encrypt() {
+		block, err := aes.NewCipher(key[:])
+
+		if err != nil {
+			return ""
+		}
+
+		gcm, err := cipher.NewGCM(block)
+
+		if err != nil {
+			return ""
+		}
+
+		nonce := make([]byte, gcm.NonceSize())
+
+		if _, err := rand.Read(nonce); err != nil {
+			return ""
+		}
+
+		return base64.RawURLEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(id), nil))
+	}
+
+	mac := hmac.New(sha256.New, key[:])
+	mac.Write([]byte(id))
+	return id + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func decodeSessionId(value string, settings *SessionSettings) string {
+	if value == "" {
+		return ""
+	}
+
+	key := sha256.Sum256([]byte(settings.Secret()))
+
+	if settings.Encrypt() {
+		buf, err := base64.RawURLEncoding.DecodeString(value)
+
+		if err != nil {
+			return ""
+		}
+
+		block, err := aes.NewCipher(key[:])
+
+		if err != nil {
+			return ""
+		}
+
+		gcm, err := cipher.NewGCM(block)
+
+		if err != nil || len(buf) < gcm.NonceSize() {
+			return ""
+		}
+
+		plain, err := gcm.Open(nil, buf[:gcm.NonceSize()], buf[gcm.NonceSize():], nil)
+
+		if err != nil {
+			return ""
+		}
+
+		return string(plain)
+	}
+
+	idx := strings.LastIndex(value, ".")
+
+	if idx < 1 {
+		return ""
+	}
+
+	id := value[:idx]
+
+	if !hmac.Equal([]byte(encodeSessionId(id, settings)), []byte(value)) {
+		return ""
+	}
+
+	return id
+}
@@ -0,0 +1,110 @@
+package mgboot
+
+import (
+	"fmt"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type SessionSettings struct {
+	cookieName   string
+	cookiePath   string
+	cookieDomain string
+	secure       bool
+	sameSite     http.SameSite
+	ttl          time.Duration
+	store        string
+	secret       string
+	encrypt      bool
+}
+
+const sessionSecretMinLength = 32
+
+func NewSessionSettings(settings map[string]interface{}) *SessionSettings {
+	cookieName := "MGBOOTSESSID"
+
+	if s1 := castx.ToString(settings["cookieName"]); s1 != "" {
+		cookieName = s1
+	}
+
+	cookiePath := "/"
+
+	if s1 := castx.ToString(settings["cookiePath"]); s1 != "" {
+		cookiePath = s1
+	}
+
+	var sameSite http.SameSite
+
+	switch strings.ToLower(castx.ToString(settings["sameSite"])) {
+	case "strict":
+		sameSite = http.SameSiteStrictMode
+	case "none":
+		sameSite = http.SameSiteNoneMode
+	default:
+		sameSite = http.SameSiteLaxMode
+	}
+
+	ttl := 30 * time.Minute
+
+	if d1, ok := settings["ttl"].(time.Duration); ok && d1 > 0 {
+		ttl = d1
+	} else if s1, ok := settings["ttl"].(string); ok && s1 != "" {
+		ttl = castx.ToDuration(s1)
+	}
+
+	secret := castx.ToString(settings["secret"])
+
+	if len(secret) < sessionSecretMinLength {
+		panic(fmt.Errorf("in mgboot.NewSessionSettings function, secret must be at least %d bytes", sessionSecretMinLength))
+	}
+
+	return &SessionSettings{
+		cookieName:   cookieName,
+		cookiePath:   cookiePath,
+		cookieDomain: castx.ToString(settings["cookieDomain"]),
+		secure:       castx.ToBool(settings["secure"]),
+		sameSite:     sameSite,
+		ttl:          ttl,
+		store:        castx.ToString(settings["store"]),
+		secret:       secret,
+		encrypt:      castx.ToBool(settings["encrypt"]),
+	}
+}
+
+func (st *SessionSettings) CookieName() string {
+	return st.cookieName
+}
+
+func (st *SessionSettings) CookiePath() string {
+	return st.cookiePath
+}
+
+func (st *SessionSettings) CookieDomain() string {
+	return st.cookieDomain
+}
+
+func (st *SessionSettings) Secure() bool {
+	return st.secure
+}
+
+func (st *SessionSettings) SameSite() http.SameSite {
+	return st.sameSite
+}
+
+func (st *SessionSettings) Ttl() time.Duration {
+	return st.ttl
+}
+
+func (st *SessionSettings) Store() string {
+	return st.store
+}
+
+func (st *SessionSettings) Secret() string {
+	return st.secret
+}
+
+func (st *SessionSettings) Encrypt() bool {
+	return st.encrypt
+}
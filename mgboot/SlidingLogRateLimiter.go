@@ -0,0 +1,70 @@
+package mgboot
+
+import (
+	"sync"
+	"time"
+)
+
+type slidingLogRateLimiter struct {
+	mu       sync.Mutex
+	total    int
+	duration time.Duration
+	logs     map[string][]time.Time
+}
+
+func NewSlidingLogRateLimiter(total int, duration time.Duration) *slidingLogRateLimiter {
+	return &slidingLogRateLimiter{
+		total:    total,
+		duration: duration,
+		logs:     map[string][]time.Time{},
+	}
+}
+
+func (l *slidingLogRateLimiter) GetAlgorithmName() string {
+	return "slidingLog"
+}
+
+func (l *slidingLogRateLimiter) Allow(key string) RateLimitResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := rateLimiterNow()
+	windowStart := now.Add(-l.duration)
+	entries := l.logs[key]
+	idx := 0
+
+	for idx < len(entries) && !entries[idx].After(windowStart) {
+		idx++
+	}
+
+	entries = entries[idx:]
+
+	if len(entries) < 1 {
+		l.cleanup(windowStart)
+	}
+
+	result := RateLimitResult{Limit: l.total}
+
+	if len(entries) < l.total {
+		entries = append(entries, now)
+		result.Allowed = true
+	} else {
+		result.RetryAfter = entries[0].Add(l.duration).Sub(now)
+	}
+
+	l.logs[key] = entries
+	result.Remaining = l.total - len(entries)
+	result.ResetAfter = entries[0].Add(l.duration).Sub(now)
+	return result
+}
+
+func (l *slidingLogRateLimiter) cleanup(windowStart time.Time) {
+	if len(l.logs) < 10000 {
+		return
+	}
+
+	for key, entries := range l.logs {
+		if len(entries) < 1 || !entries[len(entries)-1].After(windowStart) {
+			delete(l.logs, key)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package mgboot
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+type slidingWindowState struct {
+	windowStart time.Time
+	prevCount   int
+	currCount   int
+}
+
+type slidingWindowRateLimiter struct {
+	mu       sync.Mutex
+	total    int
+	duration time.Duration
+	windows  map[string]*slidingWindowState
+}
+
+func NewSlidingWindowRateLimiter(total int, duration time.Duration) *slidingWindowRateLimiter {
+	return &slidingWindowRateLimiter{
+		total:    total,
+		duration: duration,
+		windows:  map[string]*slidingWindowState{},
+	}
+}
+
+func (l *slidingWindowRateLimiter) GetAlgorithmName() string {
+	return "slidingWindow"
+}
+
+func (l *slidingWindowRateLimiter) Allow(key string) RateLimitResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := rateLimiterNow()
+	windowStart := now.Truncate(l.duration)
+	state, ok := l.windows[key]
+
+	if !ok {
+		l.cleanup(windowStart)
+		state = &slidingWindowState{windowStart: windowStart}
+		l.windows[key] = state
+	}
+
+	if !state.windowStart.Equal(windowStart) {
+		if windowStart.Sub(state.windowStart) == l.duration {
+			state.prevCount = state.currCount
+		} else {
+			state.prevCount = 0
+		}
+
+		state.currCount = 0
+		state.windowStart = windowStart
+	}
+
+	elapsed := now.Sub(windowStart).Seconds() / l.duration.Seconds()
+	estimated := float64(state.prevCount)*(1-elapsed) + float64(state.currCount)
+	result := RateLimitResult{Limit: l.total}
+
+	if estimated+1 <= float64(l.total) {
+		state.currCount++
+		estimated++
+		result.Allowed = true
+	} else if state.prevCount > 0 {
+		excess := estimated + 1 - float64(l.total)
+		wait := excess / float64(state.prevCount) * l.duration.Seconds()
+		result.RetryAfter = time.Duration(wait * float64(time.Second))
+	} else {
+		result.RetryAfter = windowStart.Add(l.duration).Sub(now)
+	}
+
+	result.Remaining = int(math.Max(0, math.Floor(float64(l.total)-estimated)))
+	result.ResetAfter = windowStart.Add(l.duration).Sub(now)
+	return result
+}
+
+func (l *slidingWindowRateLimiter) cleanup(windowStart time.Time) {
+	if len(l.windows) < 10000 {
+		return
+	}
+
+	for key, state := range l.windows {
+		if windowStart.Sub(state.windowStart) > l.duration {
+			delete(l.windows, key)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package mgboot
+
+import "time"
+
+type TimeoutError struct {
+	timeout    time.Duration
+	statusCode int
+}
+
+func NewTimeoutError(timeout time.Duration, statusCode ...int) TimeoutError {
+	code := 504
+
+	if len(statusCode) > 0 && statusCode[0] >= 400 {
+		code = statusCode[0]
+	}
+
+	return TimeoutError{timeout: timeout, statusCode: code}
+}
+
+func (ex TimeoutError) Error() string {
+	return "request timeout after " + ex.timeout.String()
+}
+
+func (ex TimeoutError) Timeout() time.Duration {
+	return ex.timeout
+}
+
+func (ex TimeoutError) StatusCode() int {
+	return ex.statusCode
+}
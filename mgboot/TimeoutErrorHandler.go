@@ -0,0 +1,28 @@
+package mgboot
+
+type timeoutErrorHandler struct {
+}
+
+func NewTimeoutErrorHandler() *timeoutErrorHandler {
+	return &timeoutErrorHandler{}
+}
+
+func (h *timeoutErrorHandler) GetErrorName() string {
+	return "builtin.TimeoutError"
+}
+
+func (h *timeoutErrorHandler) MatchError(err error) bool {
+	if _, ok := err.(TimeoutError); ok {
+		return true
+	}
+
+	return false
+}
+
+func (h *timeoutErrorHandler) HandleError(err error) ResponsePayload {
+	if ex, ok := err.(TimeoutError); ok {
+		return NewHttpErrorResponse(ex.StatusCode())
+	}
+
+	return NewHttpErrorResponse(504)
+}
@@ -0,0 +1,77 @@
+package mgboot
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+type tokenBucketState struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+type tokenBucketRateLimiter struct {
+	mu       sync.Mutex
+	total    int
+	duration time.Duration
+	buckets  map[string]*tokenBucketState
+}
+
+func NewTokenBucketRateLimiter(total int, duration time.Duration) *tokenBucketRateLimiter {
+	return &tokenBucketRateLimiter{
+		total:    total,
+		duration: duration,
+		buckets:  map[string]*tokenBucketState{},
+	}
+}
+
+func (l *tokenBucketRateLimiter) GetAlgorithmName() string {
+	return "tokenBucket"
+}
+
+func (l *tokenBucketRateLimiter) Allow(key string) RateLimitResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := rateLimiterNow()
+	rate := float64(l.total) / l.duration.Seconds()
+	state, ok := l.buckets[key]
+
+	if !ok {
+		l.cleanup(now)
+		state = &tokenBucketState{tokens: float64(l.total), lastRefill: now}
+		l.buckets[key] = state
+	}
+
+	elapsed := now.Sub(state.lastRefill).Seconds()
+
+	if elapsed > 0 {
+		state.tokens = math.Min(float64(l.total), state.tokens+elapsed*rate)
+		state.lastRefill = now
+	}
+
+	result := RateLimitResult{Limit: l.total}
+
+	if state.tokens >= 1 {
+		state.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - state.tokens) / rate * float64(time.Second))
+	}
+
+	result.Remaining = int(math.Floor(state.tokens))
+	result.ResetAfter = time.Duration((float64(l.total) - state.tokens) / rate * float64(time.Second))
+	return result
+}
+
+func (l *tokenBucketRateLimiter) cleanup(now time.Time) {
+	if len(l.buckets) < 10000 {
+		return
+	}
+
+	for key, state := range l.buckets {
+		if now.Sub(state.lastRefill) > l.duration {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-common/util/fsx"
+	"os"
+	"strings"
+	"time"
+)
+
+type TusSettings struct {
+	dir          string
+	basePath     string
+	maxSize      int64
+	maxChunkSize int64
+	expiration   time.Duration
+	store        string
+}
+
+func NewTusSettings(settings map[string]interface{}) *TusSettings {
+	dir := castx.ToString(settings["dir"])
+
+	if dir == "" {
+		dir = "datadir:tus"
+	}
+
+	dir = strings.TrimRight(strings.ReplaceAll(fsx.GetRealpath(dir), "\\", "/"), "/")
+
+	if stat, err := os.Stat(dir); err != nil || !stat.IsDir() {
+		os.MkdirAll(dir, 0755)
+	}
+
+	basePath := "/" + strings.Trim(castx.ToString(settings["basePath"]), "/")
+
+	var maxSize int64
+
+	if n1, err := castx.ToDataSizeE(settings["maxSize"]); err == nil && n1 > 0 {
+		maxSize = n1
+	}
+
+	var maxChunkSize int64
+
+	if n1, err := castx.ToDataSizeE(settings["maxChunkSize"]); err == nil && n1 > 0 {
+		maxChunkSize = n1
+	}
+
+	expiration := 24 * time.Hour
+
+	if d1, ok := settings["expiration"].(time.Duration); ok && d1 > 0 {
+		expiration = d1
+	} else if s1, ok := settings["expiration"].(string); ok && s1 != "" {
+		if d1, err := time.ParseDuration(s1); err == nil && d1 > 0 {
+			expiration = d1
+		}
+	}
+
+	return &TusSettings{
+		dir:          dir,
+		basePath:     basePath,
+		maxSize:      maxSize,
+		maxChunkSize: maxChunkSize,
+		expiration:   expiration,
+		store:        castx.ToString(settings["store"]),
+	}
+}
+
+func (st *TusSettings) Dir() string {
+	return st.dir
+}
+
+func (st *TusSettings) BasePath() string {
+	return st.basePath
+}
+
+func (st *TusSettings) MaxSize() int64 {
+	return st.maxSize
+}
+
+func (st *TusSettings) MaxChunkSize() int64 {
+	return st.maxChunkSize
+}
+
+func (st *TusSettings) Expiration() time.Duration {
+	return st.expiration
+}
+
+func (st *TusSettings) Store() string {
+	return st.store
+}
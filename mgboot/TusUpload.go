@@ -0,0 +1,21 @@
+package mgboot
+
+import "time"
+
+type TusUpload struct {
+	Id        string            `json:"id"`
+	Length    int64             `json:"length"`
+	Offset    int64             `json:"offset"`
+	Metadata  map[string]string `json:"metadata"`
+	FilePath  string            `json:"filePath"`
+	CreatedAt time.Time         `json:"createdAt"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+func (u *TusUpload) IsComplete() bool {
+	return u.Offset >= u.Length
+}
+
+func (u *TusUpload) IsExpired() bool {
+	return !u.ExpiresAt.IsZero() && time.Now().After(u.ExpiresAt)
+}
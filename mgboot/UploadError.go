@@ -0,0 +1,24 @@
+package mgboot
+
+type UploadError struct {
+	errorTips  string
+	statusCode int
+}
+
+func NewUploadError(errorTips string, statusCode ...int) UploadError {
+	code := 400
+
+	if len(statusCode) > 0 && statusCode[0] >= 400 {
+		code = statusCode[0]
+	}
+
+	return UploadError{errorTips: errorTips, statusCode: code}
+}
+
+func (ex UploadError) Error() string {
+	return ex.errorTips
+}
+
+func (ex UploadError) StatusCode() int {
+	return ex.statusCode
+}
@@ -0,0 +1,28 @@
+package mgboot
+
+type uploadErrorHandler struct {
+}
+
+func NewUploadErrorHandler() *uploadErrorHandler {
+	return &uploadErrorHandler{}
+}
+
+func (h *uploadErrorHandler) GetErrorName() string {
+	return "builtin.UploadError"
+}
+
+func (h *uploadErrorHandler) MatchError(err error) bool {
+	if _, ok := err.(UploadError); ok {
+		return true
+	}
+
+	return false
+}
+
+func (h *uploadErrorHandler) HandleError(err error) ResponsePayload {
+	if ex, ok := err.(UploadError); ok {
+		return NewHttpErrorResponse(ex.StatusCode())
+	}
+
+	return NewHttpErrorResponse(400)
+}
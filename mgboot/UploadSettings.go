@@ -0,0 +1,122 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"strings"
+)
+
+type UploadSettings struct {
+	maxFileSize       int64
+	maxFileCount      int
+	allowedExtensions []string
+	allowedMimeTypes  []string
+	storage           string
+	keyPrefix         string
+	dedupe            bool
+}
+
+func NewUploadSettings(settings map[string]interface{}) *UploadSettings {
+	maxFileSize := int64(10 * 1024 * 1024)
+
+	if n1, err := castx.ToDataSizeE(settings["maxFileSize"]); err == nil && n1 > 0 {
+		maxFileSize = n1
+	}
+
+	maxFileCount := 10
+
+	if n1 := castx.ToInt(settings["maxFileCount"]); n1 > 0 {
+		maxFileCount = n1
+	}
+
+	allowedExtensions := make([]string, 0)
+
+	for _, s1 := range toAuthorizeStringSlice(settings["allowedExtensions"]) {
+		allowedExtensions = append(allowedExtensions, strings.ToLower(strings.TrimPrefix(s1, ".")))
+	}
+
+	allowedMimeTypes := make([]string, 0)
+
+	for _, s1 := range toAuthorizeStringSlice(settings["allowedMimeTypes"]) {
+		allowedMimeTypes = append(allowedMimeTypes, strings.ToLower(s1))
+	}
+
+	storage := castx.ToString(settings["storage"])
+
+	if storage == "" {
+		storage = "default"
+	}
+
+	return &UploadSettings{
+		maxFileSize:       maxFileSize,
+		maxFileCount:      maxFileCount,
+		allowedExtensions: allowedExtensions,
+		allowedMimeTypes:  allowedMimeTypes,
+		storage:           storage,
+		keyPrefix:         strings.Trim(castx.ToString(settings["keyPrefix"]), "/"),
+		dedupe:            castx.ToBool(settings["dedupe"]),
+	}
+}
+
+func (st *UploadSettings) MaxFileSize() int64 {
+	return st.maxFileSize
+}
+
+func (st *UploadSettings) MaxFileCount() int {
+	return st.maxFileCount
+}
+
+func (st *UploadSettings) AllowedExtensions() []string {
+	return st.allowedExtensions
+}
+
+func (st *UploadSettings) AllowedMimeTypes() []string {
+	return st.allowedMimeTypes
+}
+
+func (st *UploadSettings) Storage() string {
+	return st.storage
+}
+
+func (st *UploadSettings) KeyPrefix() string {
+	return st.keyPrefix
+}
+
+func (st *UploadSettings) Dedupe() bool {
+	return st.dedupe
+}
+
+func (st *UploadSettings) IsExtensionAllowed(ext string) bool {
+	if len(st.allowedExtensions) < 1 {
+		return true
+	}
+
+	ext = strings.ToLower(strings.TrimPrefix(ext, "."))
+
+	for _, s1 := range st.allowedExtensions {
+		if s1 == ext {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (st *UploadSettings) IsMimeTypeAllowed(mimeType string) bool {
+	if len(st.allowedMimeTypes) < 1 {
+		return true
+	}
+
+	mimeType = strings.ToLower(strings.TrimSpace(strings.Split(mimeType, ";")[0]))
+
+	for _, s1 := range st.allowedMimeTypes {
+		if s1 == mimeType || s1 == "*/*" {
+			return true
+		}
+
+		if strings.HasSuffix(s1, "/*") && strings.HasPrefix(mimeType, strings.TrimSuffix(s1, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,11 @@
+package mgboot
+
+import "io"
+
+type UploadStorage interface {
+	Save(key string, reader io.Reader, size int64, contentType string) error
+	Exists(key string) bool
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+	Url(key string) string
+}
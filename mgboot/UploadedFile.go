@@ -0,0 +1,13 @@
+package mgboot
+
+type UploadedFile struct {
+	FieldName    string `json:"fieldName"`
+	OriginalName string `json:"originalName"`
+	Extension    string `json:"extension"`
+	MimeType     string `json:"mimeType"`
+	Size         int64  `json:"size"`
+	Hash         string `json:"hash"`
+	Key          string `json:"key"`
+	Url          string `json:"url"`
+	Deduplicated bool   `json:"deduplicated"`
+}
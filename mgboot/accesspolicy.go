@@ -0,0 +1,138 @@
+package mgboot
+
+import (
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-common/util/fsx"
+	"github.com/meiguonet/mgboot-go-common/util/jsonx"
+	"io/ioutil"
+	"strings"
+	"sync"
+)
+
+var policies = make([]*Policy, 0)
+var policiesLock = &sync.RWMutex{}
+
+func WithPolicies(defines ...[]map[string]interface{}) {
+	entries := make([]map[string]interface{}, 0)
+
+	if len(defines) > 0 && len(defines[0]) > 0 {
+		entries = defines[0]
+	}
+
+	if len(entries) < 1 {
+		entries = AppConf.GetMapSlice("policies")
+	}
+
+	list := make([]*Policy, 0, len(entries))
+
+	for _, entry := range entries {
+		list = append(list, NewPolicy(entry))
+	}
+
+	policiesLock.Lock()
+	policies = list
+	policiesLock.Unlock()
+}
+
+func WithPolicyFile(fpath string) error {
+	fpath = fsx.GetRealpath(fpath)
+	buf, err := ioutil.ReadFile(fpath)
+
+	if err != nil {
+		return err
+	}
+
+	contents := strings.TrimSpace(string(buf))
+	var items []interface{}
+
+	if strings.HasPrefix(contents, "[") {
+		items = jsonx.ArrayFrom(contents)
+	} else {
+		items = castx.ToSlice(jsonx.MapFrom(contents)["policies"])
+	}
+
+	entries := make([]map[string]interface{}, 0, len(items))
+
+	for _, item := range items {
+		if map1 := castx.ToStringMap(item); len(map1) > 0 {
+			entries = append(entries, map1)
+		}
+	}
+
+	if len(entries) < 1 {
+		return fmt.Errorf("no policy found in file: %s", fpath)
+	}
+
+	WithPolicies(entries)
+	return nil
+}
+
+func GetPolicies() []*Policy {
+	policiesLock.RLock()
+	defer policiesLock.RUnlock()
+	return policies
+}
+
+func EvaluatePolicies(req *Request, action, resource string) PolicyDecision {
+	claims := jwt.MapClaims{}
+
+	if req != nil {
+		if token := req.GetVerifiedJwt(); token != nil {
+			if mc, ok := token.Claims.(jwt.MapClaims); ok {
+				claims = mc
+			}
+		}
+	}
+
+	decision := PolicyDecision{reason: "no matching policy"}
+
+	for _, p := range GetPolicies() {
+		if !p.Match(req, claims, action, resource) {
+			continue
+		}
+
+		if p.IsDeny() {
+			decision = PolicyDecision{policyId: p.Id(), reason: "denied by policy"}
+			break
+		}
+
+		if !decision.allowed {
+			decision = PolicyDecision{allowed: true, policyId: p.Id(), reason: "allowed by policy"}
+		}
+	}
+
+	logPolicyDecision(claims, action, resource, decision)
+	return decision
+}
+
+func Authorize(req *Request, action, resource string) bool {
+	return EvaluatePolicies(req, action, resource).Allowed()
+}
+
+func logPolicyDecision(claims jwt.MapClaims, action, resource string, decision PolicyDecision) {
+	if !PolicyDecisionLogEnabled() {
+		return
+	}
+
+	sb := strings.Builder{}
+
+	if decision.Allowed() {
+		sb.WriteString("allow")
+	} else {
+		sb.WriteString("deny")
+	}
+
+	sb.WriteString(" sub=" + castx.ToString(claims["sub"]))
+	sb.WriteString(" action=" + action)
+	sb.WriteString(" resource=" + resource)
+
+	if decision.PolicyId() != "" {
+		sb.WriteString(" policy=" + decision.PolicyId())
+	}
+
+	sb.WriteString(", " + decision.Reason())
+	PolicyDecisionLogger().Info(sb.String())
+}
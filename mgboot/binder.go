@@ -0,0 +1,487 @@
+package mgboot
+
+import (
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/go-errors/errors"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// only these tags route a dto to BindRequest, plain json dtos keep using the json binder
+var bindingTagNames = []string{"path", "query", "header", "form", "claim"}
+var timeType = reflect.TypeOf(time.Time{})
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func BindRequest(req *Request, dto interface{}) error {
+	rv := reflect.ValueOf(dto)
+
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("in mgboot.BindRequest function, argument #1 must be a struct pointer")
+	}
+
+	bindErrors := map[string]string{}
+	bindRequestStruct(req, rv.Elem(), req.GetMap(), bindErrors)
+
+	if len(bindErrors) > 0 {
+		return NewValidateError(bindErrors)
+	}
+
+	return nil
+}
+
+func hasBindingTags(dto interface{}) bool {
+	rt := reflect.TypeOf(dto)
+
+	if rt == nil {
+		return false
+	}
+
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	if rt.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+
+		for _, tagName := range bindingTagNames {
+			if _, ok := field.Tag.Lookup(tagName); ok {
+				return true
+			}
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && hasBindingTags(reflect.New(field.Type).Interface()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func bindRequestStruct(req *Request, rv reflect.Value, data map[string]interface{}, bindErrors map[string]string) {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fv := rv.Field(i)
+
+		if field.PkgPath != "" || !fv.CanSet() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			bindRequestStruct(req, fv, data, bindErrors)
+			continue
+		}
+
+		errorKey := bindingFieldName(field)
+
+		if errorKey == "-" {
+			continue
+		}
+
+		raw, found := lookupBindingValue(req, field, data)
+
+		if !found {
+			dv, ok := field.Tag.Lookup("default")
+
+			if !ok {
+				continue
+			}
+
+			raw = dv
+		}
+
+		if policy := GetSanitizePolicy(field.Tag.Get("sanitize")); policy != nil {
+			raw = sanitizeValue(raw, policy)
+		}
+
+		if err := setBindingValue(fv, raw); err != nil {
+			bindErrors[errorKey] = fmt.Sprintf("%s参数类型错误", errorKey)
+		}
+	}
+}
+
+func bindingFieldName(field reflect.StructField) string {
+	if s1 := field.Tag.Get("json"); s1 != "" {
+		if name := strings.Split(s1, ",")[0]; name != "" {
+			return name
+		}
+	}
+
+	for _, tagName := range bindingTagNames {
+		if s1 := field.Tag.Get(tagName); s1 != "" {
+			return strings.Split(s1, ",")[0]
+		}
+	}
+
+	return field.Name
+}
+
+func lookupBindingValue(req *Request, field reflect.StructField, data map[string]interface{}) (interface{}, bool) {
+	if name := field.Tag.Get("path"); name != "" {
+		if value := req.ctx.Param(name); value != "" {
+			return value, true
+		}
+
+		return nil, false
+	}
+
+	if name := field.Tag.Get("query"); name != "" {
+		return lookupBindingParam(req.GetQueryValues(), name)
+	}
+
+	if name := field.Tag.Get("header"); name != "" {
+		if values := req.ctx.Request.Header.Values(name); len(values) > 0 {
+			return values, true
+		}
+
+		return nil, false
+	}
+
+	if name := field.Tag.Get("form"); name != "" {
+		return lookupBindingParam(req.GetFormValues(), name)
+	}
+
+	if name := field.Tag.Get("claim"); name != "" {
+		tk := req.GetVerifiedJwt()
+
+		if tk == nil {
+			return nil, false
+		}
+
+		claims, ok := tk.Claims.(jwt.MapClaims)
+
+		if !ok {
+			return nil, false
+		}
+
+		value, found := claims[name]
+		return value, found && value != nil
+	}
+
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+
+	if name == "" {
+		name = field.Name
+	}
+
+	value, found := data[name]
+	return value, found && value != nil
+}
+
+func lookupBindingParam(values url.Values, name string) (interface{}, bool) {
+	if items, ok := values[name]; ok {
+		return items, true
+	}
+
+	if items, ok := values[name+"[]"]; ok {
+		return items, true
+	}
+
+	value, found := parseBracketParams(values)[name]
+	return value, found && value != nil
+}
+
+func setBindingValue(fv reflect.Value, raw interface{}) error {
+	if raw == nil {
+		return nil
+	}
+
+	if fv.Kind() == reflect.Ptr {
+		value := reflect.New(fv.Type().Elem())
+
+		if err := setBindingValue(value.Elem(), raw); err != nil {
+			return err
+		}
+
+		fv.Set(value)
+		return nil
+	}
+
+	if fv.Kind() != reflect.Slice && fv.Kind() != reflect.Array {
+		if values, ok := raw.([]string); ok {
+			if len(values) < 1 {
+				return nil
+			}
+
+			raw = values[0]
+		}
+	}
+
+	switch {
+	case fv.Type() == durationType:
+		d1, err := toBindingDuration(raw)
+
+		if err != nil {
+			return err
+		}
+
+		fv.SetInt(int64(d1))
+		return nil
+	case fv.Type() == timeType:
+		t1, err := toBindingTime(raw)
+
+		if err != nil {
+			return err
+		}
+
+		fv.Set(reflect.ValueOf(t1))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		s1, err := castx.ToStringE(raw)
+
+		if err != nil {
+			return err
+		}
+
+		fv.SetString(s1)
+	case reflect.Bool:
+		b1, err := castx.ToBoolE(raw)
+
+		if err != nil {
+			return err
+		}
+
+		fv.SetBool(b1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n1, err := castx.ToInt64E(raw)
+
+		if err != nil || fv.OverflowInt(n1) {
+			return fmt.Errorf("cannot convert %v to %s", raw, fv.Type())
+		}
+
+		fv.SetInt(n1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n1, err := castx.ToInt64E(raw)
+
+		if err != nil || n1 < 0 || fv.OverflowUint(uint64(n1)) {
+			return fmt.Errorf("cannot convert %v to %s", raw, fv.Type())
+		}
+
+		fv.SetUint(uint64(n1))
+	case reflect.Float32, reflect.Float64:
+		n1, err := castx.ToFloat64E(raw)
+
+		if err != nil {
+			return err
+		}
+
+		fv.SetFloat(n1)
+	case reflect.Slice:
+		items := toBindingSlice(raw)
+		slice := reflect.MakeSlice(fv.Type(), len(items), len(items))
+
+		for idx, item := range items {
+			if err := setBindingValue(slice.Index(idx), item); err != nil {
+				return err
+			}
+		}
+
+		fv.Set(slice)
+	case reflect.Map:
+		map1, err := castx.ToStringMapE(raw)
+
+		if err != nil || fv.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("cannot convert %v to %s", raw, fv.Type())
+		}
+
+		map2 := reflect.MakeMapWithSize(fv.Type(), len(map1))
+
+		for key, value := range map1 {
+			item := reflect.New(fv.Type().Elem()).Elem()
+
+			if err := setBindingValue(item, value); err != nil {
+				return err
+			}
+
+			map2.SetMapIndex(reflect.ValueOf(key).Convert(fv.Type().Key()), item)
+		}
+
+		fv.Set(map2)
+	case reflect.Struct:
+		map1, err := castx.ToStringMapE(raw)
+
+		if err != nil {
+			return err
+		}
+
+		return bindMapToStruct(fv, map1)
+	case reflect.Interface:
+		if !reflect.TypeOf(raw).AssignableTo(fv.Type()) {
+			return fmt.Errorf("cannot convert %v to %s", raw, fv.Type())
+		}
+
+		fv.Set(reflect.ValueOf(raw))
+	default:
+		return fmt.Errorf("unsupported field type: %s", fv.Type())
+	}
+
+	return nil
+}
+
+func bindMapToStruct(rv reflect.Value, data map[string]interface{}) error {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fv := rv.Field(i)
+
+		if field.PkgPath != "" || !fv.CanSet() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := bindMapToStruct(fv, data); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		raw, found := data[name]
+
+		if !found {
+			dv, ok := field.Tag.Lookup("default")
+
+			if !ok {
+				continue
+			}
+
+			raw = dv
+		}
+
+		if policy := GetSanitizePolicy(field.Tag.Get("sanitize")); policy != nil {
+			raw = sanitizeValue(raw, policy)
+		}
+
+		if err := setBindingValue(fv, raw); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func toBindingSlice(raw interface{}) []interface{} {
+	switch v := raw.(type) {
+	case []interface{}:
+		return v
+	case []string:
+		if len(v) == 1 && strings.Contains(v[0], ",") {
+			return toBindingSlice(v[0])
+		}
+
+		items := make([]interface{}, 0, len(v))
+
+		for _, s1 := range v {
+			items = append(items, s1)
+		}
+
+		return items
+	case string:
+		items := make([]interface{}, 0)
+
+		for _, s1 := range strings.Split(v, ",") {
+			if s1 = strings.TrimSpace(s1); s1 != "" {
+				items = append(items, s1)
+			}
+		}
+
+		return items
+	}
+
+	rv := reflect.ValueOf(raw)
+
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		items := make([]interface{}, 0, rv.Len())
+
+		for i := 0; i < rv.Len(); i++ {
+			items = append(items, rv.Index(i).Interface())
+		}
+
+		return items
+	}
+
+	return []interface{}{raw}
+}
+
+func toBindingDuration(raw interface{}) (time.Duration, error) {
+	switch v := raw.(type) {
+	case time.Duration:
+		return v, nil
+	case string:
+		if d1, err := time.ParseDuration(v); err == nil {
+			return d1, nil
+		}
+
+		n1, err := castx.ToInt64E(v)
+
+		if err != nil {
+			return 0, err
+		}
+
+		return time.Duration(n1) * time.Millisecond, nil
+	}
+
+	n1, err := castx.ToInt64E(raw)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(n1) * time.Millisecond, nil
+}
+
+func toBindingTime(raw interface{}) (time.Time, error) {
+	if t1, ok := raw.(time.Time); ok {
+		return t1, nil
+	}
+
+	if s1, ok := raw.(string); ok {
+		layouts := []string{
+			time.RFC3339Nano,
+			"2006-01-02 15:04:05",
+			"2006-01-02T15:04:05",
+			"2006-01-02",
+		}
+
+		for _, layout := range layouts {
+			if t1, err := time.ParseInLocation(layout, s1, time.Local); err == nil {
+				return t1, nil
+			}
+		}
+	}
+
+	n1, err := castx.ToInt64E(raw)
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot convert %v to time.Time", raw)
+	}
+
+	if n1 > 1e12 {
+		return time.Unix(0, n1*int64(time.Millisecond)), nil
+	}
+
+	return time.Unix(n1, 0), nil
+}
@@ -0,0 +1,69 @@
+package mgboot
+
+import (
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"net/http/httptest"
+	"testing"
+)
+
+type binderJsonDto struct {
+	Name string `json:"name"`
+}
+
+type binderClaimDto struct {
+	UserId int64          `claim:"uid"`
+	Page   int            `query:"page" default:"1"`
+	Extra  binderStringer `query:"extra"`
+}
+
+type binderStringer interface {
+	String() string
+}
+
+func newBinderTestRequest(target string, tk *jwt.Token) *Request {
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("GET", target, nil)
+
+	if tk != nil {
+		ctx.Set("JwtToken", tk)
+	}
+
+	return NewRequest(ctx)
+}
+
+func TestHasBindingTags(t *testing.T) {
+	if hasBindingTags(&binderJsonDto{}) {
+		t.Error("a json only dto must keep using the json binder")
+	}
+
+	if !hasBindingTags(&binderClaimDto{}) {
+		t.Error("a dto with claim/query tags must be routed to BindRequest")
+	}
+}
+
+func TestBindRequestClaimRequiresVerifiedJwt(t *testing.T) {
+	claims := jwt.MapClaims{"uid": 42}
+	dto := binderClaimDto{}
+
+	if err := BindRequest(newBinderTestRequest("/", &jwt.Token{Claims: claims}), &dto); err != nil || dto.UserId != 0 {
+		t.Fatalf("claims of an unverified token must not be bound, got %d %v", dto.UserId, err)
+	}
+
+	if err := BindRequest(newBinderTestRequest("/?page=3", &jwt.Token{Claims: claims, Valid: true}), &dto); err != nil {
+		t.Fatal(err)
+	}
+
+	if dto.UserId != 42 || dto.Page != 3 {
+		t.Fatalf("unexpected dto: %+v", dto)
+	}
+}
+
+func TestBindRequestInterfaceField(t *testing.T) {
+	dto := binderClaimDto{}
+
+	if err := BindRequest(newBinderTestRequest("/?extra=abc", nil), &dto); err == nil {
+		t.Fatal("expected a value not assignable to the interface field to be rejected")
+	}
+}
@@ -0,0 +1,263 @@
+package mgboot
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/meiguonet/mgboot-go-common/util/jsonx"
+	"github.com/meiguonet/mgboot-go-common/util/mapx"
+	"github.com/vmihailenco/msgpack/v5"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+type BodyDecoder func(body []byte) (map[string]interface{}, error)
+
+type bodyDecoderEntry struct {
+	contentType string
+	decoder     BodyDecoder
+}
+
+// request bodies are not limited by default, SaveUploadedFiles applies its own limit
+var maxBodySize int64
+var bodyParamPrecedence = "body"
+var bodyDecoders = make([]bodyDecoderEntry, 0)
+
+func MaxBodySize(size ...int64) int64 {
+	if len(size) > 0 {
+		maxBodySize = size[0]
+	}
+
+	return maxBodySize
+}
+
+// @param string mode body|query
+func BodyParamPrecedence(mode ...string) string {
+	if len(mode) > 0 && (mode[0] == "body" || mode[0] == "query") {
+		bodyParamPrecedence = mode[0]
+	}
+
+	return bodyParamPrecedence
+}
+
+func WithBodyDecoder(contentType string, decoder BodyDecoder) {
+	contentType = strings.ToLower(contentType)
+
+	if contentType == "" || decoder == nil {
+		return
+	}
+
+	for idx, entry := range bodyDecoders {
+		if entry.contentType == contentType {
+			bodyDecoders[idx].decoder = decoder
+			return
+		}
+	}
+
+	bodyDecoders = append(bodyDecoders, bodyDecoderEntry{contentType: contentType, decoder: decoder})
+}
+
+func isBodyMethod(method string) bool {
+	switch method {
+	case "POST", "PUT", "PATCH", "DELETE":
+		return true
+	}
+
+	return false
+}
+
+func isFormContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	return strings.Contains(contentType, "application/x-www-form-urlencoded") ||
+		strings.Contains(contentType, "multipart/form-data")
+}
+
+func isDecodableContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+
+	for _, entry := range bodyDecoders {
+		if strings.Contains(contentType, entry.contentType) {
+			return true
+		}
+	}
+
+	return strings.Contains(contentType, "json") ||
+		strings.Contains(contentType, "xml") ||
+		strings.Contains(contentType, "msgpack")
+}
+
+func isBodyTooLarge(ctx *gin.Context) bool {
+	if maxBodySize < 1 {
+		return false
+	}
+
+	return ctx.Request.ContentLength > maxBodySize
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	if err != nil && strings.Contains(err.Error(), "request body too large") {
+		b.exceeded = true
+	}
+
+	return n, err
+}
+
+// multipart parsing reports the truncated body as a malformed part, so the
+// limit is tracked on the reader instead of relying on the returned error
+func isBodyLimitExceeded(ctx *gin.Context) bool {
+	v1, _ := ctx.Get("requestBodyLimited")
+
+	if body, ok := v1.(*limitedBody); ok {
+		return body.exceeded
+	}
+
+	return false
+}
+
+func limitRequestBody(ctx *gin.Context) {
+	limitRequestBodySize(ctx, maxBodySize)
+}
+
+func limitRequestBodySize(ctx *gin.Context, size int64) {
+	if size < 1 || ctx.Request.Body == nil {
+		return
+	}
+
+	if _, ok := ctx.Get("requestBodyLimited"); ok {
+		return
+	}
+
+	body := &limitedBody{ReadCloser: http.MaxBytesReader(ctx.Writer, ctx.Request.Body, size)}
+	ctx.Request.Body = body
+	ctx.Set("requestBodyLimited", body)
+}
+
+func readRawBody(ctx *gin.Context) ([]byte, error) {
+	if v1, ok := ctx.Get("requestRawBody"); ok {
+		if buf, ok := v1.([]byte); ok {
+			return buf, nil
+		}
+	}
+
+	if ctx.Request.Body == nil {
+		return []byte{}, nil
+	}
+
+	limitRequestBody(ctx)
+	buf, err := ioutil.ReadAll(ctx.Request.Body)
+
+	if err != nil {
+		return []byte{}, err
+	}
+
+	ctx.Set("requestRawBody", buf)
+	ctx.Request.Body = ioutil.NopCloser(bytes.NewReader(buf))
+	return buf, nil
+}
+
+func decodeRequestBody(req *Request) map[string]interface{} {
+	contentType := strings.ToLower(req.GetHeader("Content-Type"))
+
+	if isFormContentType(contentType) {
+		return parseBracketParams(req.GetFormValues())
+	}
+
+	for _, entry := range bodyDecoders {
+		if !strings.Contains(contentType, entry.contentType) {
+			continue
+		}
+
+		buf, _ := readRawBody(req.ctx)
+
+		if len(buf) < 1 {
+			return map[string]interface{}{}
+		}
+
+		map1, err := entry.decoder(buf)
+
+		if err != nil {
+			RuntimeLogger().Debug("fail to decode request body: " + err.Error())
+			return map[string]interface{}{}
+		}
+
+		return map1
+	}
+
+	isJson := strings.Contains(contentType, "json")
+	isXml := strings.Contains(contentType, "xml")
+	isMsgpack := strings.Contains(contentType, "msgpack")
+
+	if !isJson && !isXml && !isMsgpack {
+		return map[string]interface{}{}
+	}
+
+	buf, _ := readRawBody(req.ctx)
+
+	if len(buf) < 1 {
+		return map[string]interface{}{}
+	}
+
+	if isJson {
+		return jsonx.MapFrom(buf)
+	}
+
+	map1 := map[string]interface{}{}
+
+	if isXml {
+		for key, value := range mapx.FromXml(buf) {
+			map1[key] = value
+		}
+
+		return map1
+	}
+
+	if err := msgpack.Unmarshal(buf, &map1); err != nil {
+		RuntimeLogger().Debug("fail to decode request body: " + err.Error())
+		return map[string]interface{}{}
+	}
+
+	return map1
+}
+
+func parseRequestMap(req *Request) map[string]interface{} {
+	if v1, ok := req.ctx.Get("requestParsedMap"); ok {
+		if map1, ok := v1.(map[string]interface{}); ok {
+			return map1
+		}
+	}
+
+	query := parseBracketParams(req.GetQueryValues())
+	var body map[string]interface{}
+
+	if isBodyMethod(req.GetMethod()) {
+		body = decodeRequestBody(req)
+	}
+
+	map1 := map[string]interface{}{}
+	sources := []map[string]interface{}{query, body}
+
+	if bodyParamPrecedence == "query" {
+		sources = []map[string]interface{}{body, query}
+	}
+
+	for _, src := range sources {
+		for key, value := range src {
+			map1[key] = value
+		}
+	}
+
+	if policy := GetSanitizePolicy(globalSanitizePolicy); policy != nil {
+		sanitizeMapValues(map1, policy)
+	}
+
+	req.ctx.Set("requestParsedMap", map1)
+	return map1
+}
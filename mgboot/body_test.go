@@ -0,0 +1,53 @@
+package mgboot
+
+import (
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxBodySizeDefault(t *testing.T) {
+	if MaxBodySize() != 0 {
+		t.Fatalf("expected the body limit to be off by default, got %d", MaxBodySize())
+	}
+}
+
+func TestFormBodyTooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	WithBuiltinErrorHandlers()
+	orig := MaxBodySize()
+	MaxBodySize(16)
+	defer MaxBodySize(orig)
+
+	router := gin.New()
+	router.Use(MidRecover(), MidRequestBody())
+
+	router.POST("/", func(ctx *gin.Context) {
+		ctx.String(200, NewRequest(ctx).GetFormValues().Get("name"))
+	})
+
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+		code        int
+		contents    string
+	}{
+		{"small form", "application/x-www-form-urlencoded", "name=abc", 200, "abc"},
+		{"large form", "application/x-www-form-urlencoded", "name=" + strings.Repeat("a", 64), 413, ""},
+	}
+
+	for _, c := range cases {
+		// an unknown content length keeps the request away from the early Content-Length check
+		req := httptest.NewRequest("POST", "/", ioutil.NopCloser(strings.NewReader(c.body)))
+		req.Header.Set("Content-Type", c.contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != c.code || w.Body.String() != c.contents {
+			t.Errorf("%s: expected %d %q, got %d %q", c.name, c.code, c.contents, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package mgboot
+
+import (
+	"encoding/json"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"github.com/go-errors/errors"
+	"reflect"
+)
+
+func WithJwtClaimsType(settingsKey string, prototype interface{}) {
+	settings := GetJwtSettings(settingsKey)
+
+	if settings == nil {
+		return
+	}
+
+	settings.claimsType = toClaimsType(prototype)
+}
+
+func GetPrincipal(ctx *gin.Context) interface{} {
+	if v1, ok := ctx.Get("JwtPrincipal"); ok {
+		return v1
+	}
+
+	return nil
+}
+
+func BindPrincipal(ctx *gin.Context, dst interface{}) bool {
+	principal := GetPrincipal(ctx)
+
+	if principal == nil {
+		return false
+	}
+
+	dv := reflect.ValueOf(dst)
+
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return false
+	}
+
+	pv := reflect.ValueOf(principal)
+
+	if pv.Kind() == reflect.Ptr {
+		pv = pv.Elem()
+	}
+
+	if !pv.Type().AssignableTo(dv.Elem().Type()) {
+		return false
+	}
+
+	dv.Elem().Set(pv)
+	return true
+}
+
+func DecodeJwtClaims(token *jwt.Token, dst interface{}) error {
+	if token == nil {
+		return errors.New("in mgboot.DecodeJwtClaims function, token is nil")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+
+	if !ok {
+		return errors.New("in mgboot.DecodeJwtClaims function, token claims must be jwt.MapClaims")
+	}
+
+	buf, err := json.Marshal(claims)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(buf, dst)
+}
+
+func toClaimsType(prototype interface{}) reflect.Type {
+	if prototype == nil {
+		return nil
+	}
+
+	var rt reflect.Type
+
+	if t1, ok := prototype.(reflect.Type); ok {
+		rt = t1
+	} else {
+		rt = reflect.TypeOf(prototype)
+	}
+
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	if rt.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return rt
+}
+
+func decodeJwtPrincipal(token *jwt.Token, settings *JwtSettings) (interface{}, error) {
+	if settings == nil || settings.ClaimsType() == nil {
+		return nil, nil
+	}
+
+	principal := reflect.New(settings.ClaimsType()).Interface()
+
+	if err := DecodeJwtClaims(token, principal); err != nil {
+		return nil, err
+	}
+
+	return principal, nil
+}
+
+func claimsToMapClaims(claims interface{}) (jwt.MapClaims, error) {
+	switch v := claims.(type) {
+	case nil:
+		return jwt.MapClaims{}, nil
+	case jwt.MapClaims:
+		return v, nil
+	case map[string]interface{}:
+		return v, nil
+	}
+
+	buf, err := json.Marshal(claims)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mapClaims := jwt.MapClaims{}
+
+	if err := json.Unmarshal(buf, &mapClaims); err != nil {
+		return nil, errors.New("in mgboot.BuildJsonWebToken function, claims must be a map or struct")
+	}
+
+	return mapClaims, nil
+}
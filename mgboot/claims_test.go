@@ -0,0 +1,247 @@
+package mgboot
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type claimsTestRegistered struct {
+	Sub string `json:"sub"`
+	Iss string `json:"iss"`
+	Exp int64  `json:"exp"`
+	Iat int64  `json:"iat"`
+	Jti string `json:"jti"`
+}
+
+type claimsTestPrincipal struct {
+	Sub  string `json:"sub"`
+	Role string `json:"role"`
+	Uid  int64  `json:"uid"`
+}
+
+func writeJwtTestKeys(t *testing.T) (string, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pubBytes, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	pubFile := filepath.Join(dir, "pub.pem")
+	priFile := filepath.Join(dir, "pri.pem")
+	ioutil.WriteFile(pubFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubBytes}), 0600)
+	ioutil.WriteFile(priFile, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0600)
+	return pubFile, priFile
+}
+
+func withJwtTestSettings(t *testing.T, settingsKey string) {
+	pubFile, priFile := writeJwtTestKeys(t)
+
+	WithJwtSettings(settingsKey, map[string]interface{}{
+		"issuer":            "mgboot-test",
+		"publicKeyPemFile":  pubFile,
+		"privateKeyPemFile": priFile,
+		"claimsType":        claimsTestPrincipal{},
+	})
+
+	t.Cleanup(func() { delete(jwtSettings, settingsKey) })
+}
+
+func buildJwtTestToken(t *testing.T, settingsKey string) string {
+	token, err := BuildJsonWebToken(settingsKey, false, claimsTestPrincipal{Sub: "alice", Role: "admin", Uid: 42})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
+
+func TestMidJwtAuthDecodesPrincipal(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	WithBuiltinErrorHandlers()
+	withJwtTestSettings(t, "claimsTest")
+	withJwtTestSettings(t, "claimsForged")
+
+	router := gin.New()
+	router.Use(MidRecover(), MidJwtAuth("claimsTest"))
+
+	router.GET("/", func(ctx *gin.Context) {
+		principal, ok := GetPrincipal(ctx).(*claimsTestPrincipal)
+
+		if !ok {
+			ctx.String(200, "ok:no principal")
+			return
+		}
+
+		var claims claimsTestPrincipal
+
+		if err := NewRequest(ctx).BindClaims(&claims); err != nil || claims != *principal {
+			ctx.String(200, "ok:bind failed")
+			return
+		}
+
+		ctx.String(200, "ok:"+principal.Sub+":"+principal.Role)
+	})
+
+	cases := []struct {
+		name  string
+		token string
+		body  string
+	}{
+		{"verified", buildJwtTestToken(t, "claimsTest"), "ok:alice:admin"},
+		{"signed with another key", buildJwtTestToken(t, "claimsForged"), ""},
+		{"missing", "", ""},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest("GET", "/", nil)
+
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		body := w.Body.String()
+
+		if !strings.HasPrefix(body, "ok:") {
+			body = ""
+		}
+
+		if body != c.body {
+			t.Errorf("%s: expected %q, got %d %q", c.name, c.body, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestBindClaimsRequiresVerifiedJwt(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	withJwtTestSettings(t, "claimsTest")
+	token := buildJwtTestToken(t, "claimsTest")
+	orig := GetJwtPublicKeyPemFile()
+	WithJwtPublicKeyPemFile(GetJwtSettings("claimsTest").PublicKeyPemFile())
+	defer WithJwtPublicKeyPemFile(orig)
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("GET", "/", nil)
+	ctx.Request.Header.Set("Authorization", "Bearer "+token)
+	req := NewRequest(ctx)
+
+	if req.GetJwt() == nil {
+		t.Fatal("GetJwt should still parse the bearer token")
+	}
+
+	if req.GetVerifiedJwt() != nil {
+		t.Fatal("GetJwt must not promote the parsed token to a verified one")
+	}
+
+	var claims claimsTestPrincipal
+
+	if err := req.BindClaims(&claims); err == nil {
+		t.Fatalf("BindClaims must fail without a verified token, got %+v", claims)
+	}
+}
+
+func TestBuildJsonWebTokenWithStructClaims(t *testing.T) {
+	withJwtTestSettings(t, "claimsTest")
+	settings := GetJwtSettings("claimsTest")
+	tk, err := ParseJsonWebToken(buildJwtTestToken(t, "claimsTest"), settings.PublicKeyPemFile())
+
+	if err != nil || VerifyJsonWebToken(tk, settings) < 0 {
+		t.Fatalf("token should verify, err: %v", err)
+	}
+
+	principal, err := decodeJwtPrincipal(tk, settings)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p, ok := principal.(*claimsTestPrincipal); !ok || *p != (claimsTestPrincipal{Sub: "alice", Role: "admin", Uid: 42}) {
+		t.Fatalf("unexpected principal: %#v", principal)
+	}
+}
+
+func TestBuildJsonWebTokenKeepsRegisteredClaims(t *testing.T) {
+	withJwtTestSettings(t, "claimsTest")
+	settings := GetJwtSettings("claimsTest")
+	token, err := BuildJsonWebToken(settings, false, claimsTestRegistered{Sub: "alice"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tk, err := ParseJsonWebToken(token, settings.PublicKeyPemFile())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := tk.Claims.(jwt.MapClaims)
+
+	if castx.ToFloat64(claims["iat"]) < 1 || castx.ToFloat64(claims["exp"]) < castx.ToFloat64(claims["iat"]) {
+		t.Fatalf("zero exp and iat from the struct must not override the registered claims: %v", claims)
+	}
+
+	if claims["jti"] == "" || claims["iss"] != "mgboot-test" || claims["sub"] != "alice" {
+		t.Fatalf("unexpected claims: %v", claims)
+	}
+
+	exp := time.Now().Add(time.Minute).Unix()
+	token, _ = BuildJsonWebToken(settings, false, claimsTestRegistered{Sub: "alice", Exp: exp})
+	tk, _ = ParseJsonWebToken(token, settings.PublicKeyPemFile())
+
+	if castx.ToFloat64(tk.Claims.(jwt.MapClaims)["exp"]) != float64(exp) {
+		t.Fatal("a non-zero exp from the claims should still be used")
+	}
+}
+
+func TestMidJwtAuthMissingSettings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	WithBuiltinErrorHandlers()
+
+	cases := []struct {
+		settingsKey string
+		failClosed  bool
+		reached     bool
+	}{
+		{"", false, true},
+		{"nope", false, true},
+		{"", true, false},
+		{"nope", true, false},
+	}
+
+	for _, c := range cases {
+		router := gin.New()
+		router.Use(MidRecover(), MidJwtAuth(c.settingsKey, c.failClosed))
+
+		router.GET("/", func(ctx *gin.Context) {
+			ctx.String(200, "ok")
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		if reached := w.Body.String() == "ok"; reached != c.reached {
+			t.Errorf("key=%q failClosed=%v: expected reached=%v, got %d %q", c.settingsKey, c.failClosed, c.reached, w.Code, w.Body.String())
+		}
+	}
+}
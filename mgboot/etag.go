@@ -0,0 +1,168 @@
+package mgboot
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"github.com/gin-gonic/gin"
+	"github.com/meiguonet/mgboot-go-common/util/jsonx"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var etagMode string
+var etagModeLock = &sync.RWMutex{}
+
+// @param string mode strong|weak|off
+func EtagMode(mode ...string) string {
+	if len(mode) > 0 {
+		etagModeLock.Lock()
+
+		switch mode[0] {
+		case "strong", "weak":
+			etagMode = mode[0]
+		default:
+			etagMode = ""
+		}
+
+		etagModeLock.Unlock()
+	}
+
+	etagModeLock.RLock()
+	defer etagModeLock.RUnlock()
+	return etagMode
+}
+
+func ComputeEtag(contents string, weak ...bool) string {
+	digest := sha256.Sum256([]byte(contents))
+	tag := `"` + base64.RawURLEncoding.EncodeToString(digest[:16]) + `"`
+
+	if len(weak) > 0 && weak[0] {
+		return "W/" + tag
+	}
+
+	return tag
+}
+
+func ComputeEntityEtag(entity interface{}, weak ...bool) string {
+	if s1, ok := entity.(string); ok {
+		return ComputeEtag(s1, weak...)
+	}
+
+	opts := jsonx.NewToJsonOption().HandleTimeField().StripZeroTimePart()
+	return ComputeEtag(jsonx.ToJson(entity, opts), weak...)
+}
+
+func WithLastModified(ctx *gin.Context, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+
+	ctx.Set("ResponseLastModified", t.UTC().Truncate(time.Second))
+}
+
+// CheckIfMatch compares the If-Match header with the etag of the current entity.
+// Etags built by ComputeEtag are digests of the exact representation, so the W/
+// prefix added in weak mode is ignored here and If-Match works in both modes.
+func CheckIfMatch(ctx *gin.Context, currentEtag string) error {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+
+	if header == "" {
+		return nil
+	}
+
+	if currentEtag == "" {
+		return NewPreconditionFailedError()
+	}
+
+	if header == "*" || etagListContains(header, currentEtag) {
+		return nil
+	}
+
+	return NewPreconditionFailedError(currentEtag)
+}
+
+func CheckIfUnmodifiedSince(ctx *gin.Context, lastModified time.Time) error {
+	header := strings.TrimSpace(ctx.GetHeader("If-Unmodified-Since"))
+
+	if header == "" || ctx.GetHeader("If-Match") != "" {
+		return nil
+	}
+
+	t1, err := http.ParseTime(header)
+
+	if err != nil {
+		return nil
+	}
+
+	if lastModified.UTC().Truncate(time.Second).After(t1) {
+		return NewPreconditionFailedError()
+	}
+
+	return nil
+}
+
+func isEtagEligible(payload ResponsePayload) bool {
+	switch payload.(type) {
+	case JsonResponse, XmlResponse, HtmlResponse, PageResponse:
+		return true
+	}
+
+	return false
+}
+
+func addConditionalHeaders(ctx *gin.Context, payload ResponsePayload, contents string) bool {
+	if !isEtagEligible(payload) {
+		return false
+	}
+
+	var etag string
+
+	if mode := EtagMode(); mode != "" {
+		etag = ComputeEtag(contents, mode == "weak")
+		ctx.Header("ETag", etag)
+	}
+
+	var lastModified time.Time
+
+	if v1, ok := ctx.Get("ResponseLastModified"); ok {
+		if t1, ok := v1.(time.Time); ok {
+			lastModified = t1
+			ctx.Header("Last-Modified", t1.Format(http.TimeFormat))
+		}
+	}
+
+	method := ctx.Request.Method
+
+	if method != "GET" && method != "HEAD" {
+		return false
+	}
+
+	if header := strings.TrimSpace(ctx.GetHeader("If-None-Match")); header != "" {
+		if etag == "" {
+			return false
+		}
+
+		return header == "*" || etagListContains(header, etag)
+	}
+
+	if header := strings.TrimSpace(ctx.GetHeader("If-Modified-Since")); header != "" && !lastModified.IsZero() {
+		t1, err := http.ParseTime(header)
+		return err == nil && !lastModified.After(t1)
+	}
+
+	return false
+}
+
+func etagListContains(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+
+	for _, item := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(item), "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
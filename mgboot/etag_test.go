@@ -0,0 +1,127 @@
+package mgboot
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newEtagTestRouter(t *testing.T, mode string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	WithBuiltinErrorHandlers()
+	orig := EtagMode()
+	EtagMode(mode)
+	t.Cleanup(func() { EtagMode(orig) })
+	lastModified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	router := gin.New()
+	router.Use(MidRecover())
+
+	router.GET("/doc", func(ctx *gin.Context) {
+		WithLastModified(ctx, lastModified)
+		ctx.Set("ResponsePayload", NewHtmlResponse("hello"))
+		ctx.Next()
+	}, MidFinalStep())
+
+	router.PUT("/doc", func(ctx *gin.Context) {
+		req := NewRequest(ctx)
+
+		if err := req.CheckIfMatch(ComputeEntityEtag("hello", mode == "weak")); err != nil {
+			panic(err)
+		}
+
+		if err := CheckIfUnmodifiedSince(ctx, lastModified); err != nil {
+			panic(err)
+		}
+
+		ctx.String(200, "updated")
+	})
+
+	return router
+}
+
+func doEtagTestRequest(router *gin.Engine, method string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/doc", nil)
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestEtagNotModified(t *testing.T) {
+	for _, mode := range []string{"strong", "weak"} {
+		router := newEtagTestRouter(t, mode)
+		w := doEtagTestRequest(router, "GET", nil)
+		etag := w.Header().Get("ETag")
+
+		if w.Code != 200 || w.Body.String() != "hello" || etag != ComputeEtag("hello", mode == "weak") {
+			t.Fatalf("%s: unexpected response %d %q ETag=%q", mode, w.Code, w.Body.String(), etag)
+		}
+
+		cases := []struct {
+			name    string
+			headers map[string]string
+			code    int
+		}{
+			{"matching etag", map[string]string{"If-None-Match": etag}, 304},
+			{"etag in list", map[string]string{"If-None-Match": `"other", ` + etag}, 304},
+			{"weak form of etag", map[string]string{"If-None-Match": "W/" + ComputeEtag("hello")}, 304},
+			{"wildcard", map[string]string{"If-None-Match": "*"}, 304},
+			{"other etag", map[string]string{"If-None-Match": `"other"`}, 200},
+			{"other etag wins over date", map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": "Wed, 03 Jan 2024 00:00:00 GMT"}, 200},
+			{"not modified since", map[string]string{"If-Modified-Since": "Wed, 03 Jan 2024 00:00:00 GMT"}, 304},
+			{"modified since", map[string]string{"If-Modified-Since": "Mon, 01 Jan 2024 00:00:00 GMT"}, 200},
+		}
+
+		for _, c := range cases {
+			if w := doEtagTestRequest(router, "GET", c.headers); w.Code != c.code {
+				t.Errorf("%s %s: expected %d, got %d", mode, c.name, c.code, w.Code)
+			}
+		}
+	}
+
+	router := newEtagTestRouter(t, "off")
+
+	if w := doEtagTestRequest(router, "GET", map[string]string{"If-None-Match": "*"}); w.Code != 200 || w.Header().Get("ETag") != "" {
+		t.Fatalf("off: expected 200 without ETag, got %d ETag=%q", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestEtagPreconditionFailed(t *testing.T) {
+	for _, mode := range []string{"strong", "weak"} {
+		router := newEtagTestRouter(t, mode)
+		etag := doEtagTestRequest(router, "GET", nil).Header().Get("ETag")
+
+		cases := []struct {
+			name    string
+			headers map[string]string
+			code    int
+		}{
+			{"no precondition", nil, 200},
+			{"etag from GET", map[string]string{"If-Match": etag}, 200},
+			{"etag in list", map[string]string{"If-Match": `"other", ` + etag}, 200},
+			{"wildcard", map[string]string{"If-Match": "*"}, 200},
+			{"stale etag", map[string]string{"If-Match": `"other"`}, 412},
+			{"unmodified since", map[string]string{"If-Unmodified-Since": "Wed, 03 Jan 2024 00:00:00 GMT"}, 200},
+			{"modified since", map[string]string{"If-Unmodified-Since": "Mon, 01 Jan 2024 00:00:00 GMT"}, 412},
+		}
+
+		for _, c := range cases {
+			w := doEtagTestRequest(router, "PUT", c.headers)
+
+			if w.Code != c.code {
+				t.Errorf("%s %s: expected %d, got %d", mode, c.name, c.code, w.Code)
+			}
+
+			if c.code == http.StatusPreconditionFailed && c.headers["If-Match"] != "" && w.Header().Get("ETag") != etag {
+				t.Errorf("%s %s: 412 should carry the current ETag, got %q", mode, c.name, w.Header().Get("ETag"))
+			}
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/meiguonet/mgboot-go-common/util/numberx"
 	"github.com/meiguonet/mgboot-go-common/util/slicex"
 	"github.com/meiguonet/mgboot-go-common/util/stringx"
+	"net"
 	"strings"
 	"time"
 )
@@ -17,6 +18,7 @@ var runtimeLogger logx.Logger
 var requestLogLogger logx.Logger
 var logRequestBody bool
 var executeTimeLogLogger logx.Logger
+var policyDecisionLogger logx.Logger
 var errorHandlers = make([]ErrorHandler, 0)
 
 func RuntimeLogger(logger ...logx.Logger) logx.Logger {
@@ -77,6 +79,24 @@ func ExecuteTimeLogEnabled() bool {
 	return executeTimeLogLogger != nil
 }
 
+func PolicyDecisionLogger(logger ...logx.Logger) logx.Logger {
+	if len(logger) > 0 {
+		policyDecisionLogger = logger[0]
+	}
+
+	l := policyDecisionLogger
+
+	if l == nil {
+		l = NewNoopLogger()
+	}
+
+	return l
+}
+
+func PolicyDecisionLogEnabled() bool {
+	return policyDecisionLogger != nil
+}
+
 func LogExecuteTime(ctx *gin.Context) {
 	if !ExecuteTimeLogEnabled() {
 		return
@@ -101,7 +121,15 @@ func LogExecuteTime(ctx *gin.Context) {
 func WithBuiltinErrorHandlers() {
 	errorHandlers = []ErrorHandler{
 		NewRateLimitErrorHandler(),
+		NewLoadSheddingErrorHandler(),
+		NewTimeoutErrorHandler(),
+		NewPreconditionFailedErrorHandler(),
 		NewJwtAuthErrorHandler(),
+		NewAuthenticationErrorHandler(),
+		NewAuthorizationErrorHandler(),
+		NewCsrfErrorHandler(),
+		NewIpFilterErrorHandler(),
+		NewUploadErrorHandler(),
 		NewValidateErrorHandler(),
 	}
 }
@@ -165,64 +193,65 @@ func ErrorHandlers() []ErrorHandler {
 }
 
 func NeedCorsSupport(ctx *gin.Context) bool {
-	req := NewRequest(ctx)
-	methods := []string{"PUT", "DELETE", "CONNECT", "OPTIONS", "TRACE", "PATCH"}
-
-	if slicex.InStringSlice(req.GetMethod(), methods) {
-		return true
-	}
-
-	contentType := strings.ToLower(req.GetHeader("Content-Type"))
-
-	if strings.Contains(contentType, "application/x-www-form-urlencoded") ||
-		strings.Contains(contentType, "multipart/form-data") ||
-		strings.Contains(contentType, "text/plain") {
-		return true
-	}
-
-	headerNames := []string{
-		"Accept",
-		"Accept-Language",
-		"Content-Language",
-		"DPR",
-		"Downlink",
-		"Save-Data",
-		"Viewport-Widt",
-		"Width",
-	}
-
-	for headerName := range req.GetHeaders() {
-		if slicex.InStringSlice(headerName, headerNames) {
-			return true
-		}
-	}
+	return ctx.GetHeader("Origin") != ""
+}
 
-	return false
+func IsCorsPreflight(ctx *gin.Context) bool {
+	return strings.ToUpper(ctx.Request.Method) == "OPTIONS" &&
+		ctx.GetHeader("Origin") != "" &&
+		ctx.GetHeader("Access-Control-Request-Method") != ""
 }
 
 func AddCorsSupport(ctx *gin.Context) {
 	if !NeedCorsSupport(ctx) {
 		return
 	}
-	
-	settings := GetCorsSettings()
-	
+
+	settings := resolveCorsSettings(ctx)
+
 	if settings == nil {
 		return
 	}
 
-	allowedOrigins := settings.AllowedOrigins()
+	origin := ctx.GetHeader("Origin")
+	ctx.Writer.Header().Add("Vary", "Origin")
 
-	if slicex.InStringSlice("*", allowedOrigins) {
+	if !settings.MatchOrigin(origin) {
+		return
+	}
+
+	if settings.AllowAnyOrigin() && !settings.AllowCredentials() {
 		ctx.Header("Access-Control-Allow-Origin", "*")
 	} else {
-		ctx.Header("Access-Control-Allow-Origin", strings.Join(allowedOrigins, ", "))
+		ctx.Header("Access-Control-Allow-Origin", origin)
+	}
+
+	if settings.AllowCredentials() {
+		ctx.Header("Access-Control-Allow-Credentials", "true")
 	}
 
-	allowedHeaders := settings.AllowedHeaders()
+	if IsCorsPreflight(ctx) {
+		ctx.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+		ctx.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		ctx.Header("Access-Control-Allow-Methods", strings.Join(settings.AllowedMethods(), ", "))
+		allowedHeaders := settings.AllowedHeaders()
+
+		if slicex.InStringSlice("*", allowedHeaders) {
+			if s1 := ctx.GetHeader("Access-Control-Request-Headers"); s1 != "" {
+				ctx.Header("Access-Control-Allow-Headers", s1)
+			}
+		} else if len(allowedHeaders) > 0 {
+			ctx.Header("Access-Control-Allow-Headers", strings.Join(allowedHeaders, ", "))
+		}
+
+		maxAge := settings.MaxAge()
+
+		if maxAge > 0 {
+			n1 := castx.ToInt64(maxAge.Seconds())
+			ctx.Header("Access-Control-Max-Age", fmt.Sprintf("%d", n1))
+		}
 
-	if len(allowedHeaders) > 0 {
-		ctx.Header("Access-Control-Allow-Headers", strings.Join(allowedHeaders, ", "))
+		return
 	}
 
 	exposedHeaders := settings.ExposedHeaders()
@@ -230,17 +259,38 @@ func AddCorsSupport(ctx *gin.Context) {
 	if len(exposedHeaders) > 0 {
 		ctx.Header("Access-Control-Expose-Headers", strings.Join(exposedHeaders, ", "))
 	}
+}
 
-	maxAge := settings.MaxAge()
+func CheckCorsPreflight(ctx *gin.Context) bool {
+	settings := resolveCorsSettings(ctx)
 
-	if maxAge > 0 {
-		n1 := castx.ToInt64(maxAge.Seconds())
-		ctx.Header("Access-Control-Max-Age", fmt.Sprintf("%d", n1))
+	if settings == nil || !settings.MatchOrigin(ctx.GetHeader("Origin")) {
+		return false
 	}
 
-	if settings.AllowCredentials() {
-		ctx.Header("Access-Control-Allow-Credentials", "true")
+	if !settings.IsMethodAllowed(ctx.GetHeader("Access-Control-Request-Method")) {
+		return false
 	}
+
+	headerNames := make([]string, 0)
+
+	for _, name := range strings.Split(ctx.GetHeader("Access-Control-Request-Headers"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			headerNames = append(headerNames, name)
+		}
+	}
+
+	return settings.AreHeadersAllowed(headerNames)
+}
+
+func resolveCorsSettings(ctx *gin.Context) *CorsSettings {
+	if v1, ok := ctx.Get("CorsSettings"); ok {
+		if settings, ok := v1.(*CorsSettings); ok && settings != nil {
+			return settings
+		}
+	}
+
+	return GetCorsSettingsByPath(ctx.Request.URL.Path)
 }
 
 func AddPoweredBy(ctx *gin.Context) {
@@ -253,6 +303,70 @@ func AddPoweredBy(ctx *gin.Context) {
 	ctx.Header("X-Powered-By", poweredBy)
 }
 
+func isSecureRequest(ctx *gin.Context) bool {
+	if ctx.Request.TLS != nil {
+		return true
+	}
+
+	remoteIp, _, err := net.SplitHostPort(strings.TrimSpace(ctx.Request.RemoteAddr))
+
+	if err != nil {
+		remoteIp = strings.TrimSpace(ctx.Request.RemoteAddr)
+	}
+
+	if !IsTrustedProxy(remoteIp) {
+		return false
+	}
+
+	return strings.ToLower(strings.TrimSpace(ctx.GetHeader("X-Forwarded-Proto"))) == "https"
+}
+
+func AddSecurityHeaders(ctx *gin.Context) {
+	settings := GetSecurityHeadersSettings()
+
+	if settings == nil {
+		return
+	}
+
+	if isSecureRequest(ctx) && settings.HstsMaxAge() > 0 {
+		hsts := fmt.Sprintf("max-age=%d", castx.ToInt64(settings.HstsMaxAge().Seconds()))
+
+		if settings.HstsIncludeSubDomains() {
+			hsts += "; includeSubDomains"
+		}
+
+		if settings.HstsPreload() {
+			hsts += "; preload"
+		}
+
+		ctx.Header("Strict-Transport-Security", hsts)
+	}
+
+	if csp := settings.ContentSecurityPolicy(); csp != "" {
+		if strings.Contains(csp, "{nonce}") {
+			csp = strings.ReplaceAll(csp, "{nonce}", ensureCspNonce(ctx))
+		}
+
+		ctx.Header("Content-Security-Policy", csp)
+	}
+
+	if s1 := settings.ContentTypeOptions(); s1 != "" {
+		ctx.Header("X-Content-Type-Options", s1)
+	}
+
+	if s1 := settings.FrameOptions(); s1 != "" {
+		ctx.Header("X-Frame-Options", s1)
+	}
+
+	if s1 := settings.ReferrerPolicy(); s1 != "" {
+		ctx.Header("Referrer-Policy", s1)
+	}
+
+	if s1 := settings.PermissionsPolicy(); s1 != "" {
+		ctx.Header("Permissions-Policy", s1)
+	}
+}
+
 func calcElapsedTime(ctx *gin.Context) string {
 	var execStart time.Time
 	v1, _ := ctx.Get("ExecStart")
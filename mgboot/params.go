@@ -0,0 +1,178 @@
+package mgboot
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func parseBracketParams(values url.Values) map[string]interface{} {
+	map1 := map[string]interface{}{}
+	keys := make([]string, 0, len(values))
+
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		items := values[key]
+
+		if key == "" || len(items) < 1 {
+			continue
+		}
+
+		path := splitBracketKey(key)
+
+		if len(path) < 1 {
+			continue
+		}
+
+		if len(path) == 1 {
+			if len(items) == 1 {
+				map1[path[0]] = items[0]
+				continue
+			}
+
+			list := make([]interface{}, 0, len(items))
+
+			for _, item := range items {
+				list = append(list, item)
+			}
+
+			map1[path[0]] = list
+			continue
+		}
+
+		for _, item := range items {
+			setBracketParam(map1, path, item)
+		}
+	}
+
+	for key, value := range map1 {
+		map1[key] = normalizeBracketParam(value)
+	}
+
+	return map1
+}
+
+func splitBracketKey(key string) []string {
+	idx := strings.Index(key, "[")
+
+	if idx < 1 || !strings.HasSuffix(key, "]") {
+		return []string{key}
+	}
+
+	path := []string{key[:idx]}
+	rest := key[idx:]
+
+	for rest != "" {
+		if !strings.HasPrefix(rest, "[") {
+			return []string{key}
+		}
+
+		end := strings.Index(rest, "]")
+
+		if end < 0 {
+			return []string{key}
+		}
+
+		path = append(path, rest[1:end])
+		rest = rest[end+1:]
+	}
+
+	return path
+}
+
+func setBracketParam(map1 map[string]interface{}, path []string, value string) {
+	name := path[0]
+
+	if len(path) == 1 {
+		map1[name] = value
+		return
+	}
+
+	if path[1] == "" {
+		list, _ := map1[name].([]interface{})
+
+		if len(path) == 2 {
+			map1[name] = append(list, value)
+			return
+		}
+
+		child := map[string]interface{}{}
+		setBracketParam(child, append([]string{"0"}, path[2:]...), value)
+		map1[name] = append(list, child["0"])
+		return
+	}
+
+	child, ok := map1[name].(map[string]interface{})
+
+	if !ok {
+		child = map[string]interface{}{}
+		map1[name] = child
+	}
+
+	setBracketParam(child, path[1:], value)
+}
+
+func normalizeBracketParam(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		for idx, item := range v {
+			v[idx] = normalizeBracketParam(item)
+		}
+
+		return v
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeBracketParam(item)
+		}
+
+		list := make([]interface{}, len(v))
+
+		for key, item := range v {
+			idx, err := strconv.Atoi(key)
+
+			if err != nil || idx < 0 || idx >= len(v) {
+				return v
+			}
+
+			list[idx] = item
+		}
+
+		return list
+	}
+
+	return value
+}
+
+// legacyParamMap flattens the parsed params back to the scalar map GetMap used to
+// return, mapx.BindToDto and the validatex rules only understand scalar values
+func legacyParamMap(req *Request, map1 map[string]interface{}) map[string]interface{} {
+	map2 := map[string]interface{}{}
+
+	for key, value := range map1 {
+		if list, ok := value.([]interface{}); ok {
+			if len(list) < 1 {
+				continue
+			}
+
+			value = list[0]
+		}
+
+		map2[key] = value
+	}
+
+	for _, values := range []url.Values{req.GetQueryValues(), req.GetFormValues()} {
+		for key, items := range values {
+			if _, ok := map2[key]; !ok && len(items) > 0 {
+				map2[key] = items[0]
+			}
+		}
+	}
+
+	return map2
+}
@@ -0,0 +1,99 @@
+package mgboot
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type paramsLegacyDto struct {
+	Id   int    `DtoBind:"MapKey:id"`
+	Name string `DtoBind:"MapKey:name"`
+}
+
+func newParamsTestRequest(target string) *Request {
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("GET", target, nil)
+	return NewRequest(ctx)
+}
+
+func TestGetMapBracketParams(t *testing.T) {
+	cases := []struct {
+		name   string
+		target string
+		key    string
+		want   interface{}
+	}{
+		{"single value", "/?a=1", "a", "1"},
+		{"repeated key", "/?a=1&a=2", "a", []interface{}{"1", "2"}},
+		{"empty brackets", "/?tag[]=a&tag[]=b", "tag", []interface{}{"a", "b"}},
+		{"named bracket", "/?filter[name]=x&filter[age]=18", "filter", map[string]interface{}{"name": "x", "age": "18"}},
+		{"dense indices", "/?a[1]=y&a[0]=x", "a", []interface{}{"x", "y"}},
+		{"sparse indices", "/?a[0]=x&a[5]=y", "a", map[string]interface{}{"0": "x", "5": "y"}},
+		{"nested", "/?filter[user][name]=x", "filter", map[string]interface{}{"user": map[string]interface{}{"name": "x"}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := newParamsTestRequest(c.target).GetMap()[c.key]
+
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %#v, want %#v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestLegacyParamMap(t *testing.T) {
+	req := newParamsTestRequest("/?a=1&a=2&tag[]=x&tag[]=y&filter[name]=z")
+	map1 := legacyParamMap(req, req.GetMap())
+
+	want := map[string]interface{}{
+		"a":            "1",
+		"tag":          "x",
+		"tag[]":        "x",
+		"filter":       map[string]interface{}{"name": "z"},
+		"filter[name]": "z",
+	}
+
+	if !reflect.DeepEqual(map1, want) {
+		t.Errorf("got %#v, want %#v", map1, want)
+	}
+}
+
+func TestDtoBindWithRepeatedKeys(t *testing.T) {
+	dto := paramsLegacyDto{}
+
+	if err := newParamsTestRequest("/?id=7&id=8&name=tom").DtoBind(&dto); err != nil {
+		t.Fatal(err)
+	}
+
+	if dto.Id != 7 || dto.Name != "tom" {
+		t.Errorf("got %+v, want the first value of each key", dto)
+	}
+}
+
+func TestMidValidateWithRepeatedKeys(t *testing.T) {
+	handler := MidValidate([]string{"id@Int", "tag@Required"})
+
+	for target, want := range map[string]bool{"/?id=1&id=2&tag[]=a&tag[]=b": true, "/?id=x&tag[]=a": false} {
+		passed := func() (passed bool) {
+			defer func() {
+				if r := recover(); r != nil {
+					if _, ok := r.(ValidateError); !ok {
+						panic(r)
+					}
+				}
+			}()
+
+			handler(newParamsTestRequest(target).ctx)
+			return true
+		}()
+
+		if passed != want {
+			t.Errorf("%s: passed = %v, want %v", target, passed, want)
+		}
+	}
+}
@@ -3,10 +3,19 @@ package mgboot
 import (
 	"github.com/meiguonet/mgboot-go-common/util/fsx"
 	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
 var ratelimiterLuaFile string
 var ratelimiterCacheDir string
+var rateLimitKeyFuncs = map[string]RateLimitKeyFunc{}
+var concurrencyLimiters = map[string]*ConcurrencyLimiter{}
+var concurrencyLimitersLock = &sync.Mutex{}
+
+type RateLimitKeyFunc func(req *Request) string
 
 func WithRatelimiterLuaFile(fpath string) {
 	fpath = fsx.GetRealpath(fpath)
@@ -31,3 +40,95 @@ func WithRatelimiterCacheDir(dir string) {
 func RatelimiterCacheDir() string {
 	return ratelimiterCacheDir
 }
+
+func WithRateLimitKeyFunc(name string, fn RateLimitKeyFunc) {
+	if name == "" || fn == nil {
+		return
+	}
+
+	rateLimitKeyFuncs[name] = fn
+}
+
+func GetRateLimitKeyFunc(name string) RateLimitKeyFunc {
+	return rateLimitKeyFuncs[name]
+}
+
+func WithConcurrencyLimiter(name string, settings map[string]interface{}) *ConcurrencyLimiter {
+	concurrencyLimitersLock.Lock()
+	defer concurrencyLimitersLock.Unlock()
+
+	if limiter, ok := concurrencyLimiters[name]; ok {
+		return limiter
+	}
+
+	limiter := NewConcurrencyLimiter(name, settings)
+	concurrencyLimiters[name] = limiter
+	return limiter
+}
+
+func GetConcurrencyLimiter(name string) *ConcurrencyLimiter {
+	concurrencyLimitersLock.Lock()
+	defer concurrencyLimitersLock.Unlock()
+	return concurrencyLimiters[name]
+}
+
+func GetConcurrencyLimiterStats() []ConcurrencyLimiterStats {
+	concurrencyLimitersLock.Lock()
+	limiters := make([]*ConcurrencyLimiter, 0, len(concurrencyLimiters))
+
+	for _, limiter := range concurrencyLimiters {
+		limiters = append(limiters, limiter)
+	}
+
+	concurrencyLimitersLock.Unlock()
+	stats := make([]ConcurrencyLimiterStats, 0, len(limiters))
+
+	for _, limiter := range limiters {
+		stats = append(stats, limiter.Stats())
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+
+	return stats
+}
+
+type RateLimiter interface {
+	GetAlgorithmName() string
+	Allow(key string) RateLimitResult
+}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+	RetryAfter time.Duration
+}
+
+func (r RateLimitResult) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"total":      r.Limit,
+		"remaining":  r.Remaining,
+		"reset":      r.ResetAfter,
+		"retryAfter": r.RetryAfter,
+	}
+}
+
+var rateLimiterNow = time.Now
+
+func NewRateLimiter(algorithm string, total int, duration time.Duration) RateLimiter {
+	switch strings.ToLower(algorithm) {
+	case "tokenbucket", "token-bucket":
+		return NewTokenBucketRateLimiter(total, duration)
+	case "slidinglog", "sliding-log":
+		return NewSlidingLogRateLimiter(total, duration)
+	case "slidingwindow", "sliding-window":
+		return NewSlidingWindowRateLimiter(total, duration)
+	case "gcra":
+		return NewGcraRateLimiter(total, duration)
+	default:
+		return NewRedisRateLimiter(total, duration)
+	}
+}
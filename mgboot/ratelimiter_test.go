@@ -0,0 +1,116 @@
+package mgboot
+
+import (
+	"testing"
+	"time"
+)
+
+type rateLimitStep struct {
+	advance   time.Duration
+	key       string
+	allowed   bool
+	remaining int
+}
+
+func withFakeRateLimiterClock(t *testing.T) func(d time.Duration) {
+	now := time.Unix(1700000000, 0)
+	orig := rateLimiterNow
+	rateLimiterNow = func() time.Time { return now }
+	t.Cleanup(func() { rateLimiterNow = orig })
+
+	return func(d time.Duration) {
+		now = now.Add(d)
+	}
+}
+
+func runRateLimitSteps(t *testing.T, limiter RateLimiter, steps []rateLimitStep) {
+	advance := withFakeRateLimiterClock(t)
+
+	for idx, step := range steps {
+		advance(step.advance)
+		key := step.key
+
+		if key == "" {
+			key = "k1"
+		}
+
+		result := limiter.Allow(key)
+
+		if result.Allowed != step.allowed {
+			t.Fatalf("%s step #%d: expected allowed=%v, got %v", limiter.GetAlgorithmName(), idx, step.allowed, result.Allowed)
+		}
+
+		if result.Remaining != step.remaining {
+			t.Fatalf("%s step #%d: expected remaining=%d, got %d", limiter.GetAlgorithmName(), idx, step.remaining, result.Remaining)
+		}
+
+		if !result.Allowed && result.RetryAfter <= 0 {
+			t.Fatalf("%s step #%d: rejected result must carry a positive RetryAfter", limiter.GetAlgorithmName(), idx)
+		}
+
+		if result.Limit != 3 {
+			t.Fatalf("%s step #%d: expected limit=3, got %d", limiter.GetAlgorithmName(), idx, result.Limit)
+		}
+	}
+}
+
+func TestTokenBucketRateLimiter(t *testing.T) {
+	runRateLimitSteps(t, NewRateLimiter("tokenBucket", 3, 10*time.Second), []rateLimitStep{
+		{0, "", true, 2},
+		{0, "", true, 1},
+		{0, "", true, 0},
+		{0, "", false, 0},
+		{0, "k2", true, 2},
+		{3 * time.Second, "", false, 0},
+		{400 * time.Millisecond, "", true, 0},
+		{10 * time.Second, "", true, 2},
+	})
+}
+
+func TestSlidingLogRateLimiter(t *testing.T) {
+	runRateLimitSteps(t, NewRateLimiter("slidingLog", 3, 10*time.Second), []rateLimitStep{
+		{0, "", true, 2},
+		{0, "", true, 1},
+		{0, "", true, 0},
+		{0, "", false, 0},
+		{0, "k2", true, 2},
+		{9900 * time.Millisecond, "", false, 0},
+		{100 * time.Millisecond, "", true, 2},
+	})
+}
+
+func TestSlidingWindowRateLimiter(t *testing.T) {
+	runRateLimitSteps(t, NewRateLimiter("slidingWindow", 3, 10*time.Second), []rateLimitStep{
+		{0, "", true, 2},
+		{0, "", true, 1},
+		{0, "", true, 0},
+		{0, "", false, 0},
+		{0, "k2", true, 2},
+		{10 * time.Second, "", false, 0},
+		{5 * time.Second, "", true, 0},
+		{0, "", false, 0},
+		{10 * time.Second, "", true, 1},
+		{30 * time.Second, "", true, 2},
+	})
+}
+
+func TestGcraRateLimiter(t *testing.T) {
+	runRateLimitSteps(t, NewRateLimiter("gcra", 3, 10*time.Second), []rateLimitStep{
+		{0, "", true, 2},
+		{0, "", true, 1},
+		{0, "", true, 0},
+		{0, "", false, 0},
+		{0, "k2", true, 2},
+		{3 * time.Second, "", false, 0},
+		{400 * time.Millisecond, "", true, 0},
+		{20 * time.Second, "", true, 2},
+	})
+}
+
+func TestNewRateLimiterAlgorithms(t *testing.T) {
+	for _, name := range []string{"tokenBucket", "slidingLog", "slidingWindow", "gcra"} {
+		if limiter := NewRateLimiter(name, 3, time.Second); limiter.GetAlgorithmName() != name {
+			t.Errorf("expected %s limiter, got %s", name, limiter.GetAlgorithmName())
+		}
+	}
+}
@@ -1,19 +1,23 @@
 package mgboot
 
 import (
+	"context"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
+	"github.com/go-errors/errors"
+	"github.com/gogo/protobuf/proto"
 	"github.com/meiguonet/mgboot-go-common/enum/RegexConst"
 	"github.com/meiguonet/mgboot-go-common/util/castx"
-	"github.com/meiguonet/mgboot-go-common/util/jsonx"
 	"github.com/meiguonet/mgboot-go-common/util/mapx"
 	"github.com/meiguonet/mgboot-go-common/util/slicex"
 	"github.com/meiguonet/mgboot-go-common/util/stringx"
 	"math"
 	"mime/multipart"
+	"net"
 	"net/url"
 	"regexp"
 	"strings"
+	"time"
 )
 
 type Request struct {
@@ -24,6 +28,18 @@ func NewRequest(ctx *gin.Context) *Request {
 	return &Request{ctx: ctx}
 }
 
+func (r *Request) Context() context.Context {
+	return r.ctx.Request.Context()
+}
+
+func (r *Request) Deadline() (time.Time, bool) {
+	return r.ctx.Request.Context().Deadline()
+}
+
+func (r *Request) IsTimedOut() bool {
+	return IsRequestTimedOut(r.ctx) || r.ctx.Request.Context().Err() == context.DeadlineExceeded
+}
+
 func (r *Request) GetMethod() string {
 	return strings.ToUpper(r.ctx.Request.Method)
 }
@@ -85,6 +101,22 @@ func (r *Request) GetQueryParams() map[string]string {
 	return map1
 }
 
+func (r *Request) GetQueryValues() url.Values {
+	query := r.ctx.Request.URL.RawQuery
+
+	if query == "" {
+		return url.Values{}
+	}
+
+	values, err := url.ParseQuery(query)
+
+	if err != nil {
+		return url.Values{}
+	}
+
+	return values
+}
+
 func (r *Request) GetQueryString(urlencode ...bool) string {
 	params := r.GetQueryParams()
 
@@ -152,25 +184,84 @@ func (r *Request) GetFormData() map[string]string {
 	return map1
 }
 
+func (r *Request) GetFormValues() url.Values {
+	r.ctx.PostForm("NonExistsKey")
+
+	if len(r.ctx.Request.PostForm) < 1 {
+		return url.Values{}
+	}
+
+	return r.ctx.Request.PostForm
+}
+
 func (r *Request) GetClientIp() string {
-	ip := r.GetHeader("X-Forwarded-For")
+	remoteIp, _, err := net.SplitHostPort(strings.TrimSpace(r.ctx.Request.RemoteAddr))
 
-	if ip == "" {
-		ip = r.GetHeader("X-Real-IP")
+	if err != nil {
+		remoteIp = strings.TrimSpace(r.ctx.Request.RemoteAddr)
+	}
+
+	if !IsTrustedProxy(remoteIp) {
+		return remoteIp
+	}
+
+	hops := r.getForwardedFor()
+
+	if len(hops) < 1 {
+		if ip := strings.TrimSpace(r.GetHeader("X-Real-IP")); ip != "" {
+			return ip
+		}
+
+		return remoteIp
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		if !IsTrustedProxy(hops[i]) {
+			return hops[i]
+		}
 	}
 
-	if ip == "" {
-		ip = r.ctx.ClientIP()
+	return hops[0]
+}
+
+func (r *Request) getForwardedFor() []string {
+	hops := make([]string, 0)
+
+	if forwarded := r.ctx.Request.Header.Values("Forwarded"); len(forwarded) > 0 {
+		for _, element := range strings.Split(strings.Join(forwarded, ","), ",") {
+			for _, pair := range strings.Split(element, ";") {
+				pair = strings.TrimSpace(pair)
+
+				if len(pair) < 4 || !strings.EqualFold(pair[:4], "for=") {
+					continue
+				}
+
+				node := strings.Trim(strings.TrimSpace(pair[4:]), `"`)
+
+				if strings.HasPrefix(node, "[") {
+					node = strings.TrimPrefix(stringx.SubstringBefore(node, "]"), "[")
+				} else if strings.Count(node, ":") == 1 {
+					node = stringx.SubstringBefore(node, ":")
+				}
+
+				hops = append(hops, node)
+			}
+		}
+
+		return hops
 	}
 
 	regex1 := regexp.MustCompile(RegexConst.CommaSep)
-	parts := regex1.Split(strings.TrimSpace(ip), -1)
 
-	if len(parts) < 1 {
-		return ""
+	for _, value := range r.ctx.Request.Header.Values("X-Forwarded-For") {
+		for _, ip := range regex1.Split(strings.TrimSpace(value), -1) {
+			if ip = strings.TrimSpace(ip); ip != "" {
+				hops = append(hops, ip)
+			}
+		}
 	}
 
-	return strings.TrimSpace(parts[0])
+	return hops
 }
 
 func (r *Request) PathvariableString(name string, defaultValue ...interface{}) string {
@@ -281,6 +372,41 @@ func (r *Request) ParamString(name string, defaultValue ...interface{}) string {
 	return dv
 }
 
+func (r *Request) ParamStringSlice(name string) []string {
+	values := make([]string, 0)
+
+	for _, src := range []url.Values{r.GetQueryValues(), r.GetFormValues()} {
+		values = append(values, src[name]...)
+		values = append(values, src[name+"[]"]...)
+	}
+
+	return values
+}
+
+func (r *Request) ParamIntSlice(name string) []int {
+	values := make([]int, 0)
+
+	for _, s1 := range r.ParamStringSlice(name) {
+		if n1, err := castx.ToIntE(s1); err == nil {
+			values = append(values, n1)
+		}
+	}
+
+	return values
+}
+
+func (r *Request) ParamInt64Slice(name string) []int64 {
+	values := make([]int64, 0)
+
+	for _, s1 := range r.ParamStringSlice(name) {
+		if n1, err := castx.ToInt64E(s1); err == nil {
+			values = append(values, n1)
+		}
+	}
+
+	return values
+}
+
 func (r *Request) ParamStringWithSecurityMode(name string, mode int, defaultValue ...interface{}) string {
 	var dv string
 
@@ -307,9 +433,12 @@ func (r *Request) ParamStringWithSecurityMode(name string, mode int, defaultValu
 	}
 
 	if s1, err := castx.ToStringE(map1[name]); err == nil {
+		// mode 1 keeps basic html, mode 2 reduces the value to escaped plain text
 		switch mode {
-		case 1, 2:
-			s1 = stringx.StripTags(s1)
+		case 1:
+			s1 = Sanitize("html", s1)
+		case 2:
+			s1 = Sanitize("text", s1)
 		}
 
 		return s1
@@ -318,6 +447,14 @@ func (r *Request) ParamStringWithSecurityMode(name string, mode int, defaultValu
 	return dv
 }
 
+func (r *Request) ParamStringWithPolicy(name, policy string, defaultValue ...interface{}) string {
+	return Sanitize(policy, r.ParamString(name, defaultValue...))
+}
+
+func (r *Request) Pageable(settings ...map[string]interface{}) *Pageable {
+	return NewPageable(r, settings...)
+}
+
 func (r *Request) ParamBool(name string, defaultValue ...interface{}) bool {
 	var dv bool
 
@@ -432,7 +569,15 @@ func (r *Request) ParamFloat64(name string, defaultValue ...interface{}) float64
 	return castx.ToFloat64(map1[name], dv)
 }
 
+func (r *Request) GetVerifiedJwt() *jwt.Token {
+	return GetVerifiedJwt(r.ctx)
+}
+
 func (r *Request) GetJwt() *jwt.Token {
+	if tk := r.GetVerifiedJwt(); tk != nil {
+		return tk
+	}
+
 	token := strings.TrimSpace(r.GetHeader("Authorization"))
 	token = stringx.RegexReplace(token, `[\x20\t]+`, " ")
 
@@ -448,6 +593,24 @@ func (r *Request) GetJwt() *jwt.Token {
 	return tk
 }
 
+func (r *Request) GetPrincipal() interface{} {
+	return GetPrincipal(r.ctx)
+}
+
+func (r *Request) BindClaims(dst interface{}) error {
+	if BindPrincipal(r.ctx, dst) {
+		return nil
+	}
+
+	tk := r.GetVerifiedJwt()
+
+	if tk == nil {
+		return errors.New("in mgboot.Request.BindClaims function, no verified jwt found")
+	}
+
+	return DecodeJwtClaims(tk, dst)
+}
+
 func (r *Request) JwtClaimString(name string, defaultValue ...interface{}) string {
 	var dv string
 
@@ -457,14 +620,9 @@ func (r *Request) JwtClaimString(name string, defaultValue ...interface{}) strin
 		}
 	}
 
-	token := strings.TrimSpace(r.GetHeader("Authorization"))
-	token = stringx.RegexReplace(token, `[\x20\t]+`, " ")
+	token := r.GetJwt()
 
-	if strings.Contains(token, " ") {
-		token = stringx.SubstringAfter(token, " ")
-	}
-
-	if token == "" {
+	if token == nil {
 		return dv
 	}
 
@@ -480,14 +638,9 @@ func (r *Request) JwtClaimBool(name string, defaultValue ...interface{}) bool {
 		}
 	}
 
-	token := strings.TrimSpace(r.GetHeader("Authorization"))
-	token = stringx.RegexReplace(token, `[\x20\t]+`, " ")
+	token := r.GetJwt()
 
-	if strings.Contains(token, " ") {
-		token = stringx.SubstringAfter(token, " ")
-	}
-
-	if token == "" {
+	if token == nil {
 		return dv
 	}
 
@@ -503,14 +656,9 @@ func (r *Request) JwtClaimInt(name string, defaultValue ...interface{}) int {
 		}
 	}
 
-	token := strings.TrimSpace(r.GetHeader("Authorization"))
-	token = stringx.RegexReplace(token, `[\x20\t]+`, " ")
-
-	if strings.Contains(token, " ") {
-		token = stringx.SubstringAfter(token, " ")
-	}
+	token := r.GetJwt()
 
-	if token == "" {
+	if token == nil {
 		return dv
 	}
 
@@ -526,14 +674,9 @@ func (r *Request) JwtClaimInt64(name string, defaultValue ...interface{}) int64
 		}
 	}
 
-	token := strings.TrimSpace(r.GetHeader("Authorization"))
-	token = stringx.RegexReplace(token, `[\x20\t]+`, " ")
-
-	if strings.Contains(token, " ") {
-		token = stringx.SubstringAfter(token, " ")
-	}
+	token := r.GetJwt()
 
-	if token == "" {
+	if token == nil {
 		return dv
 	}
 
@@ -549,14 +692,9 @@ func (r *Request) JwtClaimFloat32(name string, defaultValue ...interface{}) floa
 		}
 	}
 
-	token := strings.TrimSpace(r.GetHeader("Authorization"))
-	token = stringx.RegexReplace(token, `[\x20\t]+`, " ")
-
-	if strings.Contains(token, " ") {
-		token = stringx.SubstringAfter(token, " ")
-	}
+	token := r.GetJwt()
 
-	if token == "" {
+	if token == nil {
 		return dv
 	}
 
@@ -572,14 +710,9 @@ func (r *Request) JwtClaimFloat64(name string, defaultValue ...interface{}) floa
 		}
 	}
 
-	token := strings.TrimSpace(r.GetHeader("Authorization"))
-	token = stringx.RegexReplace(token, `[\x20\t]+`, " ")
+	token := r.GetJwt()
 
-	if strings.Contains(token, " ") {
-		token = stringx.SubstringAfter(token, " ")
-	}
-
-	if token == "" {
+	if token == nil {
 		return dv
 	}
 
@@ -587,14 +720,9 @@ func (r *Request) JwtClaimFloat64(name string, defaultValue ...interface{}) floa
 }
 
 func (r *Request) JwtClaimStringSlice(name string) []string {
-	token := strings.TrimSpace(r.GetHeader("Authorization"))
-	token = stringx.RegexReplace(token, `[\x20\t]+`, " ")
+	token := r.GetJwt()
 
-	if strings.Contains(token, " ") {
-		token = stringx.SubstringAfter(token, " ")
-	}
-
-	if token == "" {
+	if token == nil {
 		return make([]string, 0)
 	}
 
@@ -602,125 +730,142 @@ func (r *Request) JwtClaimStringSlice(name string) []string {
 }
 
 func (r *Request) JwtClaimIntSlice(name string) []int {
-	token := strings.TrimSpace(r.GetHeader("Authorization"))
-	token = stringx.RegexReplace(token, `[\x20\t]+`, " ")
-
-	if strings.Contains(token, " ") {
-		token = stringx.SubstringAfter(token, " ")
-	}
+	token := r.GetJwt()
 
-	if token == "" {
+	if token == nil {
 		return make([]int, 0)
 	}
 
 	return JwtClaimIntSlice(token, name)
 }
 
-func (r *Request) GetRawBody() []byte {
-	method := r.GetMethod()
-	contentType := strings.ToLower(r.GetHeader("Content-Type"))
-	isPostForm := strings.Contains(contentType, "application/x-www-form-urlencoded")
-	isMultipartForm := strings.Contains(contentType, "multipart/form-data")
+func (r *Request) CheckIfMatch(currentEtag string) error {
+	return CheckIfMatch(r.ctx, currentEtag)
+}
 
-	if method == "POST" && (isPostForm || isMultipartForm) {
-		formData := r.GetFormData()
+func (r *Request) CheckIfUnmodifiedSince(lastModified time.Time) error {
+	return CheckIfUnmodifiedSince(r.ctx, lastModified)
+}
 
-		if len(formData) < 1 {
-			return make([]byte, 0)
+func (r *Request) SetLastModified(t time.Time) {
+	WithLastModified(r.ctx, t)
+}
+
+func (r *Request) GetAuthScheme() string {
+	if v1, ok := r.ctx.Get("AuthScheme"); ok {
+		if s1, ok := v1.(string); ok {
+			return s1
 		}
+	}
 
-		values := url.Values{}
+	return ""
+}
 
-		for name, value := range formData {
-			values[name] = []string{value}
+func (r *Request) GetAuthSubject() string {
+	if v1, ok := r.ctx.Get("AuthSubject"); ok {
+		if s1, ok := v1.(string); ok {
+			return s1
 		}
+	}
+
+	return ""
+}
 
-		contents := values.Encode()
-		return []byte(contents)
+func (r *Request) Session() *Session {
+	if v1, ok := r.ctx.Get("Session"); ok {
+		if sess, ok := v1.(*Session); ok {
+			return sess
+		}
 	}
 
-	methods := []string{"POST", "PUT", "PATCH", "DELETE"}
+	return nil
+}
 
-	if !slicex.InStringSlice(method, methods) {
-		return make([]byte, 0)
+func (r *Request) CspNonce() string {
+	if GetSecurityHeadersSettings() == nil {
+		return ""
 	}
 
-	isJson := strings.Contains(contentType, "application/json")
-	isXml1 := strings.Contains(contentType, "application/xml")
-	isXml2 := strings.Contains(contentType, "text/xml")
+	return ensureCspNonce(r.ctx)
+}
 
-	if !isJson && !isXml1 && !isXml2 {
-		return make([]byte, 0)
+func (r *Request) CsrfToken() string {
+	if v1, ok := r.ctx.Get("CsrfToken"); ok {
+		if s1, ok := v1.(string); ok {
+			return s1
+		}
 	}
 
-	rawBody, ok := r.ctx.Get("requestRawBody")
+	return ""
+}
 
-	if !ok {
+func (r *Request) GetRawBody() []byte {
+	if !isBodyMethod(r.GetMethod()) {
 		return make([]byte, 0)
 	}
 
-	if buf, ok := rawBody.([]byte); ok && len(buf) > 0 {
-		return buf
+	if isFormContentType(r.GetHeader("Content-Type")) {
+		formData := r.GetFormValues()
+
+		if len(formData) < 1 {
+			return make([]byte, 0)
+		}
+
+		return []byte(formData.Encode())
 	}
 
-	return make([]byte, 0)
+	buf, _ := readRawBody(r.ctx)
+	return buf
+}
+
+func (r *Request) ProtobufBind(msg proto.Message) error {
+	return proto.Unmarshal(r.GetRawBody(), msg)
 }
 
 // @param string[]|string rules
 func (r *Request) GetMap(rules ...interface{}) map[string]interface{} {
-	method := r.GetMethod()
-	methods := []string{"POST", "PUT", "PATCH", "DELETE"}
-	contentType := strings.ToLower(r.GetHeader("Content-Type"))
-	isPostForm := strings.Contains(contentType, "application/x-www-form-urlencoded")
-	isMultipartForm := strings.Contains(contentType, "multipart/form-data")
-	isJson := strings.Contains(contentType, "application/json")
-	isXml1 := strings.Contains(contentType, "application/xml")
-	isXml2 := strings.Contains(contentType, "text/xml")
 	map1 := map[string]interface{}{}
 
-	if method == "GET" {
-		for key, value := range r.GetQueryParams() {
-			map1[key] = value
-		}
-	} else if method == "POST" && (isPostForm || isMultipartForm) {
-		for key, value := range r.GetQueryParams() {
-			map1[key] = value
-		}
-
-		for key, value := range r.GetFormData() {
-			map1[key] = value
-		}
-	} else if slicex.InStringSlice(method, methods) {
-		return map1
-	} else if isJson {
-		map1 = jsonx.MapFrom(r.GetRawBody())
-	} else if isXml1 || isXml2 {
-		map2 := mapx.FromXml(r.GetRawBody())
-
-		for key, value := range map2 {
-			map1[key] = value
-		}
+	for key, value := range parseRequestMap(r) {
+		map1[key] = value
 	}
 
-	if len(map1) < 1 {
-		return map[string]interface{}{}
-	}
-
-	if len(rules) < 1 {
+	if len(map1) < 1 || len(rules) < 1 {
 		return map1
 	}
 
 	return mapx.FromRequestParam(map1, rules...)
 }
 
-func (r *Request) DtoBind(dto interface{}) error {
-	return mapx.BindToDto(r.GetMap(), dto)
+func (r *Request) DtoBind(dto interface{}, validate ...bool) error {
+	var err error
+
+	if hasBindingTags(dto) {
+		err = BindRequest(r, dto)
+	} else {
+		err = mapx.BindToDto(legacyParamMap(r, r.GetMap()), dto)
+	}
+
+	if err != nil || len(validate) < 1 || !validate[0] {
+		return err
+	}
+
+	return ValidateDto(dto)
 }
 
 func (r *Request) GetUploadedFile(formFieldName string) *multipart.FileHeader {
-	if fh, err := r.ctx.FormFile(formFieldName); err != nil {
+	if fh, err := r.ctx.FormFile(formFieldName); err == nil {
 		return fh
 	}
 
 	return nil
 }
+
+func (r *Request) GetUploadedFiles(formFieldName string) []*multipart.FileHeader {
+	return getUploadedFileHeaders(r, formFieldName)
+}
+
+// @param *UploadSettings|map[string]interface{} settings
+func (r *Request) SaveUploadedFiles(settings interface{}, formFieldNames ...string) ([]*UploadedFile, error) {
+	return SaveUploadedFiles(r, settings, formFieldNames...)
+}
@@ -0,0 +1,370 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/stringx"
+	"golang.org/x/net/html"
+	"io"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type SanitizePolicy func(s string) string
+
+var sanitizePolicies = map[string]SanitizePolicy{
+	"none":     func(s string) string { return s },
+	"strip":    stringx.StripTags,
+	"text":     SanitizeStrictText,
+	"html":     SanitizeBasicHtml,
+	"url":      SanitizeUrl,
+	"filename": SanitizeFilename,
+}
+
+var globalSanitizePolicy string
+
+var sanitizeHtmlTags = map[string][]string{
+	"a":          {"href", "title"},
+	"b":          {},
+	"blockquote": {},
+	"br":         {},
+	"code":       {},
+	"em":         {},
+	"h1":         {},
+	"h2":         {},
+	"h3":         {},
+	"h4":         {},
+	"h5":         {},
+	"h6":         {},
+	"hr":         {},
+	"i":          {},
+	"img":        {"src", "alt", "title", "width", "height"},
+	"li":         {},
+	"ol":         {},
+	"p":          {},
+	"pre":        {},
+	"s":          {},
+	"span":       {},
+	"strong":     {},
+	"sub":        {},
+	"sup":        {},
+	"table":      {},
+	"tbody":      {},
+	"td":         {"colspan", "rowspan"},
+	"th":         {"colspan", "rowspan"},
+	"thead":      {},
+	"tr":         {},
+	"u":          {},
+	"ul":         {},
+}
+
+var sanitizeDropContentTags = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"iframe":   true,
+	"object":   true,
+	"embed":    true,
+	"noscript": true,
+	"template": true,
+	"svg":      true,
+	"math":     true,
+}
+
+var sanitizeFilenameRegex = regexp.MustCompile(`[<>:"/\\|?*\x00-\x1f]+`)
+
+func WithSanitizePolicy(name string, policy SanitizePolicy) {
+	if name == "" || policy == nil {
+		return
+	}
+
+	sanitizePolicies[name] = policy
+}
+
+func GetSanitizePolicy(name string) SanitizePolicy {
+	return sanitizePolicies[name]
+}
+
+func GlobalSanitizePolicy(name ...string) string {
+	if len(name) > 0 {
+		globalSanitizePolicy = name[0]
+	}
+
+	return globalSanitizePolicy
+}
+
+func Sanitize(policyName, s string) string {
+	policy := GetSanitizePolicy(policyName)
+
+	if policy == nil || s == "" {
+		return s
+	}
+
+	return policy(s)
+}
+
+func SanitizeStrictText(s string) string {
+	if s == "" {
+		return s
+	}
+
+	sb := strings.Builder{}
+	tokenizer := html.NewTokenizer(strings.NewReader(s))
+	var skipDepth int
+
+	for {
+		tt := tokenizer.Next()
+
+		if tt == html.ErrorToken {
+			break
+		}
+
+		switch tt {
+		case html.StartTagToken:
+			name, _ := tokenizer.TagName()
+
+			if sanitizeDropContentTags[string(name)] {
+				skipDepth++
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+
+			if sanitizeDropContentTags[string(name)] && skipDepth > 0 {
+				skipDepth--
+			}
+		case html.TextToken:
+			if skipDepth < 1 {
+				sb.WriteString(html.UnescapeString(string(tokenizer.Text())))
+			}
+		}
+	}
+
+	text := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return -1
+		}
+
+		return r
+	}, sb.String())
+
+	return html.EscapeString(strings.TrimSpace(text))
+}
+
+func SanitizeBasicHtml(s string) string {
+	if s == "" {
+		return s
+	}
+
+	sb := strings.Builder{}
+	tokenizer := html.NewTokenizer(strings.NewReader(s))
+	openTags := make([]string, 0)
+	var skipDepth int
+
+	for {
+		tt := tokenizer.Next()
+
+		if tt == html.ErrorToken {
+			if tokenizer.Err() != io.EOF {
+				return SanitizeStrictText(s)
+			}
+
+			break
+		}
+
+		token := tokenizer.Token()
+
+		if skipDepth > 0 {
+			if sanitizeDropContentTags[token.Data] {
+				switch tt {
+				case html.StartTagToken:
+					skipDepth++
+				case html.EndTagToken:
+					skipDepth--
+				}
+			}
+
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			sb.WriteString(html.EscapeString(token.Data))
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if sanitizeDropContentTags[token.Data] {
+				if tt == html.StartTagToken {
+					skipDepth++
+				}
+
+				continue
+			}
+
+			allowedAttrs, ok := sanitizeHtmlTags[token.Data]
+
+			if !ok {
+				continue
+			}
+
+			sb.WriteString("<" + token.Data)
+
+			for _, attr := range token.Attr {
+				if !policyValueIn(attr.Key, allowedAttrs) || attr.Namespace != "" {
+					continue
+				}
+
+				value := attr.Val
+
+				if attr.Key == "href" || attr.Key == "src" {
+					value = SanitizeUrl(value)
+
+					if value == "" {
+						continue
+					}
+				}
+
+				sb.WriteString(" " + attr.Key + `="` + html.EscapeString(value) + `"`)
+			}
+
+			if token.Data == "a" {
+				sb.WriteString(` rel="nofollow noopener noreferrer"`)
+			}
+
+			if isVoidHtmlTag(token.Data) {
+				sb.WriteString(" />")
+				continue
+			}
+
+			sb.WriteString(">")
+
+			if tt == html.SelfClosingTagToken {
+				sb.WriteString("</" + token.Data + ">")
+				continue
+			}
+
+			openTags = append(openTags, token.Data)
+		case html.EndTagToken:
+			idx := len(openTags) - 1
+
+			for idx >= 0 && openTags[idx] != token.Data {
+				idx--
+			}
+
+			if idx < 0 {
+				continue
+			}
+
+			for i := len(openTags) - 1; i >= idx; i-- {
+				sb.WriteString("</" + openTags[i] + ">")
+			}
+
+			openTags = openTags[:idx]
+		}
+	}
+
+	for i := len(openTags) - 1; i >= 0; i-- {
+		sb.WriteString("</" + openTags[i] + ">")
+	}
+
+	return sb.String()
+}
+
+func SanitizeUrl(s string) string {
+	s = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || unicode.IsSpace(r) && r != ' ' {
+			return -1
+		}
+
+		return r
+	}, s))
+
+	if s == "" {
+		return ""
+	}
+
+	u, err := url.Parse(s)
+
+	if err != nil {
+		return ""
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+	default:
+		return ""
+	}
+
+	if u.Scheme == "" && u.Host == "" && strings.Contains(strings.SplitN(s, "/", 2)[0], ":") {
+		return ""
+	}
+
+	return u.String()
+}
+
+func SanitizeFilename(s string) string {
+	s = filepath.Base(strings.ReplaceAll(s, "\\", "/"))
+	s = sanitizeFilenameRegex.ReplaceAllString(s, "_")
+	s = strings.Trim(s, ". ")
+
+	if len(s) > 255 {
+		ext := filepath.Ext(s)
+
+		if len(ext) > 16 {
+			ext = ""
+		}
+
+		base := s[:255-len(ext)]
+
+		for !utf8.ValidString(base) {
+			base = base[:len(base)-1]
+		}
+
+		s = base + ext
+	}
+
+	if s == "" || s == "_" {
+		return "file"
+	}
+
+	return s
+}
+
+func sanitizeMapValues(map1 map[string]interface{}, policy SanitizePolicy) {
+	for key, value := range map1 {
+		map1[key] = sanitizeValue(value, policy)
+	}
+}
+
+func sanitizeValue(value interface{}, policy SanitizePolicy) interface{} {
+	switch v := value.(type) {
+	case string:
+		return policy(v)
+	case []string:
+		values := make([]string, 0, len(v))
+
+		for _, item := range v {
+			values = append(values, policy(item))
+		}
+
+		return values
+	case []interface{}:
+		for idx, item := range v {
+			v[idx] = sanitizeValue(item, policy)
+		}
+
+		return v
+	case map[string]interface{}:
+		sanitizeMapValues(v, policy)
+		return v
+	}
+
+	return value
+}
+
+func isVoidHtmlTag(name string) bool {
+	switch name {
+	case "br", "hr", "img":
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,123 @@
+package mgboot
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var sanitizeXssVectors = []string{
+	`<a href="javascript:alert(1)">x</a>`,
+	`<a href="&#106;avascript:alert(1)">x</a>`,
+	`<a href="&#x6A;&#x61;&#x76;&#x61;&#x73;&#x63;&#x72;&#x69;&#x70;&#x74;&#x3A;alert(1)">x</a>`,
+	`<a href="java&#x09;script:alert(1)">x</a>`,
+	`<a href=" JaVaScRiPt:alert(1)">x</a>`,
+	`<a href="data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==">x</a>`,
+	`<img src="data:image/svg+xml,<svg onload=alert(1)>">`,
+	`<img src=x onerror=alert(1)>`,
+	`<p onclick="alert(1)" onmouseover='alert(1)'>x</p>`,
+	`<svg><script>alert(1)</script></svg>`,
+	`<div><script>alert(1)`,
+	`<scr<script>ipt>alert(1)</script>`,
+	`<<script>script>alert(1)<</script>/script>`,
+	`<iframe src="https://evil.example.com"></iframe>`,
+	`<b><i>unclosed <img src=x onerror=alert(1)`,
+	`<a href="vbscript:msgbox(1)">x</a>`,
+	`<style>body{background:url(javascript:alert(1))}</style>`,
+}
+
+func assertNoXss(t *testing.T, policy, input, output string) {
+	lower := strings.ToLower(output)
+
+	for _, needle := range []string{"javascript:", "vbscript:", "data:", "onerror", "onclick", "onload", "onmouseover", "<script", "<iframe", "<svg", "<style"} {
+		if strings.Contains(lower, needle) {
+			t.Errorf("%s: %q leaked %q in %q", policy, input, needle, output)
+		}
+	}
+}
+
+func TestSanitizeBasicHtmlXssVectors(t *testing.T) {
+	for _, input := range sanitizeXssVectors {
+		assertNoXss(t, "html", input, SanitizeBasicHtml(input))
+	}
+}
+
+func TestSanitizeStrictTextXssVectors(t *testing.T) {
+	for _, input := range sanitizeXssVectors {
+		output := SanitizeStrictText(input)
+
+		if strings.ContainsAny(output, "<>") {
+			t.Errorf("text: %q left markup in %q", input, output)
+		}
+	}
+}
+
+func TestSanitizeBasicHtml(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{`<p>hello <b>world</b></p>`, `<p>hello <b>world</b></p>`},
+		{`<a href="https://example.com" onclick="x()">x</a>`, `<a href="https://example.com" rel="nofollow noopener noreferrer">x</a>`},
+		{`<a href="&#106;avascript:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{`<img src=x onerror=alert(1)>`, `<img src="x" />`},
+		{`<b><i>unclosed`, `<b><i>unclosed</i></b>`},
+		{`<b><i>mis</b>nested</i>`, `<b><i>mis</i></b>nested`},
+		{`<div><script>alert(1)</script>ok</div>`, `ok`},
+		{`1 < 2 & 3`, `1 &lt; 2 &amp; 3`},
+	}
+
+	for _, c := range cases {
+		if output := SanitizeBasicHtml(c.input); output != c.expected {
+			t.Errorf("SanitizeBasicHtml(%q): expected %q, got %q", c.input, c.expected, output)
+		}
+	}
+}
+
+func TestSanitizeUrl(t *testing.T) {
+	cases := map[string]string{
+		"https://example.com/a?b=1": "https://example.com/a?b=1",
+		"/relative/path":            "/relative/path",
+		"mailto:a@example.com":      "mailto:a@example.com",
+		"javascript:alert(1)":       "",
+		"java\tscript:alert(1)":     "",
+		"  JAVASCRIPT:alert(1)":     "",
+		"data:text/html,<b>x</b>":   "",
+		"vbscript:msgbox(1)":        "",
+	}
+
+	for input, expected := range cases {
+		if output := SanitizeUrl(input); output != expected {
+			t.Errorf("SanitizeUrl(%q): expected %q, got %q", input, expected, output)
+		}
+	}
+}
+
+func TestParamStringWithSecurityMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	input := `<b>bold</b> <script>alert(1)</script>`
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("GET", "/?q="+strings.NewReplacer("<", "%3C", ">", "%3E", " ", "%20", "(", "%28", ")", "%29").Replace(input), nil)
+	req := NewRequest(ctx)
+
+	cases := []struct {
+		mode int
+		want string
+	}{
+		{0, input},
+		{1, SanitizeBasicHtml(input)},
+		{2, SanitizeStrictText(input)},
+		{9, SanitizeStrictText(input)},
+	}
+
+	for _, c := range cases {
+		if s1 := req.ParamStringWithSecurityMode("q", c.mode); s1 != c.want {
+			t.Errorf("mode %d: expected %q, got %q", c.mode, c.want, s1)
+		}
+	}
+
+	if SanitizeBasicHtml(input) == SanitizeStrictText(input) {
+		t.Error("mode 1 and mode 2 must not sanitize the same way")
+	}
+}
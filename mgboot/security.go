@@ -1,24 +1,48 @@
 package mgboot
 
 import (
+	"crypto/rand"
 	"crypto/rsa"
+	"encoding/hex"
 	"fmt"
 	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
 	"github.com/go-errors/errors"
 	"github.com/meiguonet/mgboot-go-common/AppConf"
 	"github.com/meiguonet/mgboot-go-common/util/castx"
 	"github.com/meiguonet/mgboot-go-common/util/fsx"
-	"github.com/meiguonet/mgboot-go-fiber/enum/JwtVerifyErrno"
+	"github.com/meiguonet/mgboot-go-common/util/jsonx"
+	"github.com/meiguonet/mgboot-go-gin/enum/JwtVerifyErrno"
 	"io/ioutil"
 	"math"
+	"net"
 	"os"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
+type corsSettingsEntry struct {
+	pathPrefix string
+	settings   *CorsSettings
+}
+
 var corsSettings *CorsSettings
+var corsSettingsByPath = make([]corsSettingsEntry, 0)
+var csrfSettings *CsrfSettings
+var sessionSettings *SessionSettings
+var securityHeadersSettings *SecurityHeadersSettings
+var trustedProxies = make([]*net.IPNet, 0)
+var ipFilters = map[string]*IpFilter{}
+var ipFiltersLock = &sync.RWMutex{}
 var jwtPublicKeyPemFile string
 var jwtPrivateKeyPemFile string
 var jwtSettings map[string]*JwtSettings
+var jwtRevocationStore JwtRevocationStore
+var authorizePredicates = map[string]AuthorizePredicate{}
+var authProviders = map[string]AuthProvider{}
+var oidcProviders = map[string]*oidcProvider{}
 
 func WithCorsSettings(settings ...map[string]interface{}) {
 	_settings := map[string]interface{}{}
@@ -30,12 +54,195 @@ func WithCorsSettings(settings ...map[string]interface{}) {
 	if len(_settings) < 1 {
 		_settings = AppConf.GetMap("cors")
 	}
+
+	corsSettings = NewCorsSettings(_settings)
 }
 
 func GetCorsSettings() *CorsSettings {
 	return corsSettings
 }
 
+func WithCorsSettingsForPath(pathPrefix string, settings map[string]interface{}) {
+	pathPrefix = "/" + strings.Trim(pathPrefix, "/")
+	entries := make([]corsSettingsEntry, 0, len(corsSettingsByPath)+1)
+
+	for _, entry := range corsSettingsByPath {
+		if entry.pathPrefix != pathPrefix {
+			entries = append(entries, entry)
+		}
+	}
+
+	entries = append(entries, corsSettingsEntry{pathPrefix: pathPrefix, settings: NewCorsSettings(settings)})
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return len(entries[i].pathPrefix) > len(entries[j].pathPrefix)
+	})
+
+	corsSettingsByPath = entries
+}
+
+func GetCorsSettingsByPath(path string) *CorsSettings {
+	for _, entry := range corsSettingsByPath {
+		if entry.pathPrefix == "/" || path == entry.pathPrefix || strings.HasPrefix(path, entry.pathPrefix+"/") {
+			return entry.settings
+		}
+	}
+
+	return corsSettings
+}
+
+func WithCsrfSettings(settings ...map[string]interface{}) {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	if len(_settings) < 1 {
+		_settings = AppConf.GetMap("csrf")
+	}
+
+	csrfSettings = NewCsrfSettings(_settings)
+}
+
+func GetCsrfSettings() *CsrfSettings {
+	return csrfSettings
+}
+
+func WithSessionSettings(settings ...map[string]interface{}) {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	if len(_settings) < 1 {
+		_settings = AppConf.GetMap("session")
+	}
+
+	sessionSettings = NewSessionSettings(_settings)
+}
+
+func GetSessionSettings() *SessionSettings {
+	return sessionSettings
+}
+
+func WithSecurityHeadersSettings(settings ...map[string]interface{}) {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	if len(_settings) < 1 {
+		_settings = AppConf.GetMap("securityHeaders")
+	}
+
+	securityHeadersSettings = NewSecurityHeadersSettings(_settings)
+}
+
+func GetSecurityHeadersSettings() *SecurityHeadersSettings {
+	return securityHeadersSettings
+}
+
+func WithTrustedProxies(cidrs ...[]string) {
+	entries := make([]string, 0)
+
+	if len(cidrs) > 0 && len(cidrs[0]) > 0 {
+		entries = cidrs[0]
+	}
+
+	if len(entries) < 1 {
+		entries = AppConf.GetStringSlice("trustedProxies")
+	}
+
+	trustedProxies = parseCidrList(entries)
+}
+
+func IsTrustedProxy(ip string) bool {
+	addr := net.ParseIP(strings.TrimSpace(ip))
+	return addr != nil && ipInNets(addr, trustedProxies)
+}
+
+func WithIpFilters(defines ...map[string]interface{}) {
+	entries := map[string]interface{}{}
+
+	if len(defines) > 0 && len(defines[0]) > 0 {
+		entries = defines[0]
+	}
+
+	if len(entries) < 1 {
+		entries = AppConf.GetMap("ipFilters")
+	}
+
+	filters := map[string]*IpFilter{}
+
+	for name, entry := range entries {
+		filters[name] = NewIpFilter(castx.ToStringMap(entry))
+	}
+
+	ipFiltersLock.Lock()
+	ipFilters = filters
+	ipFiltersLock.Unlock()
+}
+
+func WithIpFilterFile(fpath string) error {
+	buf, err := ioutil.ReadFile(fsx.GetRealpath(fpath))
+
+	if err != nil {
+		return err
+	}
+
+	map1 := jsonx.MapFrom(buf)
+
+	if len(map1) < 1 {
+		return fmt.Errorf("no ip filter found in file: %s", fpath)
+	}
+
+	WithIpFilters(map1)
+	return nil
+}
+
+func WatchIpFilterFile(fpath string, interval time.Duration) {
+	fpath = fsx.GetRealpath(fpath)
+
+	if interval < time.Second {
+		interval = 5 * time.Second
+	}
+
+	var lastModTime time.Time
+
+	if stat, err := os.Stat(fpath); err == nil {
+		lastModTime = stat.ModTime()
+	}
+
+	go func() {
+		for {
+			time.Sleep(interval)
+			stat, err := os.Stat(fpath)
+
+			if err != nil || !stat.ModTime().After(lastModTime) {
+				continue
+			}
+
+			lastModTime = stat.ModTime()
+
+			if err := WithIpFilterFile(fpath); err != nil {
+				RuntimeLogger().Warnf("fail to reload ip filters: %s", err.Error())
+				continue
+			}
+
+			RuntimeLogger().Info("ip filters reloaded from " + fpath)
+		}
+	}()
+}
+
+func GetIpFilter(name string) *IpFilter {
+	ipFiltersLock.RLock()
+	defer ipFiltersLock.RUnlock()
+	return ipFilters[name]
+}
+
 func WithJwtPublicKeyPemFile(fpath string) {
 	fpath = fsx.GetRealpath(fpath)
 
@@ -94,6 +301,184 @@ func GetJwtSettings(key string) *JwtSettings {
 	return jwtSettings[key]
 }
 
+func WithJwtRevocationStore(store JwtRevocationStore) {
+	jwtRevocationStore = store
+}
+
+func GetJwtRevocationStore() JwtRevocationStore {
+	return jwtRevocationStore
+}
+
+func WithOidcSettings(key string, settings ...map[string]interface{}) {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	if len(_settings) < 1 {
+		_settings = AppConf.GetMap("oidc." + key)
+	}
+
+	oidcProviders[key] = NewOidcProvider(NewOidcSettings(_settings))
+}
+
+func GetOidcProvider(key string) *oidcProvider {
+	return oidcProviders[key]
+}
+
+func WithAuthProvider(provider AuthProvider, name ...string) {
+	if provider == nil {
+		return
+	}
+
+	_name := provider.GetSchemeName()
+
+	if len(name) > 0 && name[0] != "" {
+		_name = name[0]
+	}
+
+	authProviders[_name] = provider
+}
+
+func GetAuthProvider(name string) AuthProvider {
+	if provider, ok := authProviders[name]; ok {
+		return provider
+	}
+
+	if strings.HasPrefix(name, "jwt:") {
+		return NewJwtAuthProvider(strings.TrimPrefix(name, "jwt:"))
+	}
+
+	if strings.HasPrefix(name, "oidc:") {
+		return NewOidcAuthProvider(strings.TrimPrefix(name, "oidc:"))
+	}
+
+	return nil
+}
+
+func WithAuthorizePredicate(name string, fn AuthorizePredicate) {
+	if name == "" || fn == nil {
+		return
+	}
+
+	authorizePredicates[name] = fn
+}
+
+func GetAuthorizePredicate(name string) AuthorizePredicate {
+	return authorizePredicates[name]
+}
+
+// @param *jwt.Token|string arg0
+func RevokeJsonWebToken(arg0 interface{}) bool {
+	if jwtRevocationStore == nil {
+		return false
+	}
+
+	var token *jwt.Token
+
+	if tk, ok := arg0.(*jwt.Token); ok {
+		token = tk
+	} else if s1, ok := arg0.(string); ok && s1 != "" {
+		tk, _ := ParseJsonWebToken(s1)
+		token = tk
+	}
+
+	if token == nil {
+		return false
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+
+	if !ok {
+		return false
+	}
+
+	jti := castx.ToString(claims["jti"])
+
+	if jti == "" {
+		return false
+	}
+
+	var ttl time.Duration
+
+	if exp := castx.ToInt64(claims["exp"]); exp > 0 {
+		ttl = time.Unix(exp, 0).Sub(time.Now())
+
+		if ttl <= 0 {
+			return true
+		}
+	}
+
+	return jwtRevocationStore.RevokeJti(jti, ttl)
+}
+
+func RevokeJwtByJti(jti string, ttl ...time.Duration) bool {
+	if jwtRevocationStore == nil || jti == "" {
+		return false
+	}
+
+	_ttl := maxJwtTtl()
+
+	if len(ttl) > 0 && ttl[0] > 0 {
+		_ttl = ttl[0]
+	}
+
+	return jwtRevocationStore.RevokeJti(jti, _ttl)
+}
+
+func RevokeJwtBySubject(sub string, before ...time.Time) bool {
+	if jwtRevocationStore == nil || sub == "" {
+		return false
+	}
+
+	_before := time.Now()
+
+	if len(before) > 0 && !before[0].IsZero() {
+		_before = before[0]
+	}
+
+	return jwtRevocationStore.RevokeSubject(sub, _before, maxJwtTtl())
+}
+
+func IsJsonWebTokenRevoked(token *jwt.Token) bool {
+	if jwtRevocationStore == nil || token == nil {
+		return false
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+
+	if !ok {
+		return false
+	}
+
+	if jwtRevocationStore.IsJtiRevoked(castx.ToString(claims["jti"])) {
+		return true
+	}
+
+	revokedBefore := jwtRevocationStore.SubjectRevokedBefore(castx.ToString(claims["sub"]))
+
+	if revokedBefore.IsZero() {
+		return false
+	}
+
+	return castx.ToInt64(claims["iat"]) <= revokedBefore.Unix()
+}
+
+func GetVerifiedJwt(ctx *gin.Context) *jwt.Token {
+	v1, ok := ctx.Get("JwtToken")
+
+	if !ok {
+		return nil
+	}
+
+	if tk, ok := v1.(*jwt.Token); ok && tk != nil && tk.Valid {
+		return tk
+	}
+
+	return nil
+}
+
 func ParseJsonWebToken(token string, pubpem ...string) (*jwt.Token, error) {
 	var fpath string
 
@@ -155,11 +540,16 @@ func VerifyJsonWebToken(arg0 interface{}, settings *JwtSettings) int {
 		return JwtVerifyErrno.Expired
 	}
 
+	if IsJsonWebTokenRevoked(token) {
+		return JwtVerifyErrno.Revoked
+	}
+
 	return 0
 }
 
 // @param *JwtSettings|string arg0
-func BuildJsonWebToken(arg0 interface{}, isRefreshToken bool, claims ...map[string]interface{}) (token string, err error) {
+// @param map[string]interface{}|struct claims
+func BuildJsonWebToken(arg0 interface{}, isRefreshToken bool, claims ...interface{}) (token string, err error) {
 	var settings *JwtSettings
 
 	if s1, ok := arg0.(*JwtSettings); ok && s1 != nil {
@@ -198,10 +588,24 @@ func BuildJsonWebToken(arg0 interface{}, isRefreshToken bool, claims ...map[stri
 	mapClaims := jwt.MapClaims{
 		"iss": settings.Issuer(),
 		"exp": exp,
+		"iat": time.Now().Unix(),
+		"jti": randomHexString(16),
 	}
 
-	if len(claims) > 0 && len(claims[0]) > 0 {
-		for claimName, claimValue := range claims[0] {
+	if len(claims) > 0 {
+		var extraClaims jwt.MapClaims
+		extraClaims, err = claimsToMapClaims(claims[0])
+
+		if err != nil {
+			return
+		}
+
+		for claimName, claimValue := range extraClaims {
+			// zero values from struct claims must not wipe out the registered claims
+			if _, ok := mapClaims[claimName]; ok && isEmptyClaimValue(claimValue) {
+				continue
+			}
+
 			mapClaims[claimName] = claimValue
 		}
 	}
@@ -210,6 +614,19 @@ func BuildJsonWebToken(arg0 interface{}, isRefreshToken bool, claims ...map[stri
 	return
 }
 
+func isEmptyClaimValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	if s1, ok := value.(string); ok {
+		return s1 == ""
+	}
+
+	n1, err := castx.ToFloat64E(value)
+	return err == nil && n1 == 0
+}
+
 // @param *jwt.Token|string arg0
 func JwtClaimString(arg0 interface{}, name string, defaultValue ...string) string {
 	var dv string
@@ -497,3 +914,33 @@ func loadKeyPem(typ string, arg1 interface{}) []byte {
 
 	return buf
 }
+
+func maxJwtTtl() time.Duration {
+	var ttl time.Duration
+
+	for _, settings := range jwtSettings {
+		if settings == nil {
+			continue
+		}
+
+		if settings.Ttl() > ttl {
+			ttl = settings.Ttl()
+		}
+
+		if settings.RefreshTokenTtl() > ttl {
+			ttl = settings.RefreshTokenTtl()
+		}
+	}
+
+	return ttl
+}
+
+func randomHexString(n int) string {
+	buf := make([]byte, n)
+
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(buf)
+}
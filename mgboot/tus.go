@@ -0,0 +1,445 @@
+package mgboot
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	ccachex "github.com/meiguonet/mgboot-go-common/cachex"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-gin/cachex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type TusCompleteHandler func(upload *TusUpload)
+
+const tusVersion = "1.0.0"
+
+var tusSettings *TusSettings
+var tusCompleteHandlers = make([]TusCompleteHandler, 0)
+var tusLocks = &sync.Map{}
+
+func WithTusSettings(settings ...map[string]interface{}) {
+	_settings := map[string]interface{}{}
+
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		_settings = settings[0]
+	}
+
+	if len(_settings) < 1 {
+		_settings = AppConf.GetMap("tus")
+	}
+
+	tusSettings = NewTusSettings(_settings)
+}
+
+func GetTusSettings() *TusSettings {
+	return tusSettings
+}
+
+func WithTusCompleteHandler(fn TusCompleteHandler) {
+	if fn == nil {
+		return
+	}
+
+	tusCompleteHandlers = append(tusCompleteHandlers, fn)
+}
+
+func MountTusRoutes(router gin.IRouter, relativePath ...string) {
+	if tusSettings == nil {
+		WithTusSettings()
+	}
+
+	path := tusSettings.BasePath()
+
+	if len(relativePath) > 0 && relativePath[0] != "" {
+		path = relativePath[0]
+	}
+
+	path = strings.TrimRight(path, "/")
+
+	if path == "" {
+		path = "/"
+	}
+
+	router.OPTIONS(path, tusOptions)
+	router.POST(path, tusCreate)
+	router.OPTIONS(strings.TrimRight(path, "/")+"/:tusId", tusOptions)
+	router.HEAD(strings.TrimRight(path, "/")+"/:tusId", tusHead)
+	router.PATCH(strings.TrimRight(path, "/")+"/:tusId", tusPatch)
+	router.DELETE(strings.TrimRight(path, "/")+"/:tusId", tusDelete)
+}
+
+func GetTusUpload(id string) *TusUpload {
+	if tusSettings == nil || id == "" || strings.ContainsAny(id, "/\\.") {
+		return nil
+	}
+
+	contents := castx.ToString(tusStore().Get("tus.upload." + id))
+
+	if contents == "" {
+		return nil
+	}
+
+	upload := &TusUpload{}
+
+	if err := json.Unmarshal([]byte(contents), upload); err != nil {
+		return nil
+	}
+
+	return upload
+}
+
+func CleanupExpiredTusUploads() int {
+	if tusSettings == nil {
+		return 0
+	}
+
+	entries, err := ioutil.ReadDir(tusSettings.Dir())
+
+	if err != nil {
+		return 0
+	}
+
+	var n1 int
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		upload := GetTusUpload(entry.Name())
+
+		if upload != nil && !upload.IsExpired() {
+			continue
+		}
+
+		if upload == nil && time.Since(entry.ModTime()) < tusSettings.Expiration() {
+			continue
+		}
+
+		os.Remove(filepath.Join(tusSettings.Dir(), entry.Name()))
+		tusStore().Delete("tus.upload." + entry.Name())
+		tusLocks.Delete(entry.Name())
+		n1++
+	}
+
+	return n1
+}
+
+func tusOptions(ctx *gin.Context) {
+	ctx.Header("Tus-Resumable", tusVersion)
+	ctx.Header("Tus-Version", tusVersion)
+	ctx.Header("Tus-Extension", "creation,creation-with-upload,termination,expiration")
+
+	if tusSettings.MaxSize() > 0 {
+		ctx.Header("Tus-Max-Size", fmt.Sprintf("%d", tusSettings.MaxSize()))
+	}
+
+	ctx.AbortWithStatus(204)
+}
+
+func tusCreate(ctx *gin.Context) {
+	if !checkTusResumable(ctx) {
+		return
+	}
+
+	length, err := castx.ToInt64E(ctx.GetHeader("Upload-Length"))
+
+	if err != nil || length < 0 {
+		ctx.AbortWithStatus(400)
+		return
+	}
+
+	if tusSettings.MaxSize() > 0 && length > tusSettings.MaxSize() {
+		ctx.AbortWithStatus(413)
+		return
+	}
+
+	now := time.Now()
+	id := randomHexString(16)
+
+	upload := &TusUpload{
+		Id:        id,
+		Length:    length,
+		Metadata:  parseTusMetadata(ctx.GetHeader("Upload-Metadata")),
+		FilePath:  filepath.Join(tusSettings.Dir(), id),
+		CreatedAt: now,
+		ExpiresAt: now.Add(tusSettings.Expiration()),
+	}
+
+	fd, err := os.OpenFile(upload.FilePath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+
+	if err != nil {
+		RuntimeLogger().Error(err)
+		ctx.AbortWithStatus(500)
+		return
+	}
+
+	fd.Close()
+
+	var writeErr error
+
+	if strings.ToLower(ctx.GetHeader("Content-Type")) == "application/offset+octet-stream" && ctx.Request.ContentLength != 0 {
+		limitRequestBodySize(ctx, tusSettings.MaxChunkSize())
+		writeErr = appendTusUpload(upload, ctx.Request.Body)
+	}
+
+	if !saveTusUpload(upload) {
+		os.Remove(upload.FilePath)
+		ctx.AbortWithStatus(500)
+		return
+	}
+
+	ctx.Header("Location", strings.TrimRight(ctx.Request.URL.Path, "/")+"/"+id)
+
+	if writeErr != nil {
+		abortTusWrite(ctx, upload, writeErr)
+		return
+	}
+
+	ctx.Header("Upload-Offset", fmt.Sprintf("%d", upload.Offset))
+	ctx.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	ctx.AbortWithStatus(201)
+
+	if upload.IsComplete() {
+		fireTusComplete(upload)
+	}
+}
+
+func tusHead(ctx *gin.Context) {
+	if !checkTusResumable(ctx) {
+		return
+	}
+
+	upload := GetTusUpload(ctx.Param("tusId"))
+
+	if upload == nil || upload.IsExpired() {
+		ctx.AbortWithStatus(404)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Upload-Offset", fmt.Sprintf("%d", upload.Offset))
+	ctx.Header("Upload-Length", fmt.Sprintf("%d", upload.Length))
+	ctx.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+
+	if len(upload.Metadata) > 0 {
+		ctx.Header("Upload-Metadata", encodeTusMetadata(upload.Metadata))
+	}
+
+	ctx.AbortWithStatus(200)
+}
+
+func tusPatch(ctx *gin.Context) {
+	if !checkTusResumable(ctx) {
+		return
+	}
+
+	if strings.ToLower(ctx.GetHeader("Content-Type")) != "application/offset+octet-stream" {
+		ctx.AbortWithStatus(415)
+		return
+	}
+
+	upload, unlock := lockTusUpload(ctx.Param("tusId"))
+
+	if upload == nil || upload.IsExpired() {
+		unlock()
+		ctx.AbortWithStatus(404)
+		return
+	}
+
+	defer unlock()
+
+	offset, err := castx.ToInt64E(ctx.GetHeader("Upload-Offset"))
+
+	if err != nil || offset != upload.Offset {
+		ctx.AbortWithStatus(409)
+		return
+	}
+
+	if upload.IsComplete() {
+		ctx.AbortWithStatus(409)
+		return
+	}
+
+	limitRequestBodySize(ctx, tusSettings.MaxChunkSize())
+	writeErr := appendTusUpload(upload, ctx.Request.Body)
+	upload.ExpiresAt = time.Now().Add(tusSettings.Expiration())
+
+	if !saveTusUpload(upload) {
+		ctx.AbortWithStatus(500)
+		return
+	}
+
+	if writeErr != nil {
+		abortTusWrite(ctx, upload, writeErr)
+		return
+	}
+
+	ctx.Header("Upload-Offset", fmt.Sprintf("%d", upload.Offset))
+	ctx.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	ctx.AbortWithStatus(204)
+
+	if upload.IsComplete() {
+		fireTusComplete(upload)
+	}
+}
+
+func tusDelete(ctx *gin.Context) {
+	if !checkTusResumable(ctx) {
+		return
+	}
+
+	upload, unlock := lockTusUpload(ctx.Param("tusId"))
+	defer unlock()
+
+	if upload == nil {
+		ctx.AbortWithStatus(404)
+		return
+	}
+
+	os.Remove(upload.FilePath)
+	tusStore().Delete("tus.upload." + upload.Id)
+	tusLocks.Delete(upload.Id)
+	ctx.AbortWithStatus(204)
+}
+
+// lockTusUpload takes the per-upload lock, locks are only created for uploads
+// that exist and are dropped again when the upload is gone once the lock is held
+func lockTusUpload(id string) (*TusUpload, func()) {
+	if GetTusUpload(id) == nil {
+		return nil, func() {}
+	}
+
+	v1, _ := tusLocks.LoadOrStore(id, &sync.Mutex{})
+	lock := v1.(*sync.Mutex)
+	lock.Lock()
+	upload := GetTusUpload(id)
+
+	if upload == nil {
+		tusLocks.Delete(id)
+	}
+
+	return upload, lock.Unlock
+}
+
+// the received bytes are kept so the client can resume from the saved offset
+func abortTusWrite(ctx *gin.Context, upload *TusUpload, err error) {
+	ctx.Header("Upload-Offset", fmt.Sprintf("%d", upload.Offset))
+
+	if isBodyLimitExceeded(ctx) {
+		ctx.AbortWithStatus(413)
+		return
+	}
+
+	RuntimeLogger().Error(err)
+	ctx.AbortWithStatus(500)
+}
+
+func checkTusResumable(ctx *gin.Context) bool {
+	ctx.Header("Tus-Resumable", tusVersion)
+
+	if ctx.GetHeader("Tus-Resumable") != tusVersion {
+		ctx.Header("Tus-Version", tusVersion)
+		ctx.AbortWithStatus(412)
+		return false
+	}
+
+	return true
+}
+
+func appendTusUpload(upload *TusUpload, body io.Reader) error {
+	if body == nil {
+		return nil
+	}
+
+	fd, err := os.OpenFile(upload.FilePath, os.O_WRONLY|os.O_APPEND, 0644)
+
+	if err != nil {
+		return err
+	}
+
+	defer fd.Close()
+	written, err := io.Copy(fd, io.LimitReader(body, upload.Length-upload.Offset))
+	upload.Offset += written
+	return err
+}
+
+func saveTusUpload(upload *TusUpload) bool {
+	buf, err := json.Marshal(upload)
+
+	if err != nil {
+		return false
+	}
+
+	return tusStore().Set("tus.upload."+upload.Id, string(buf), tusSettings.Expiration())
+}
+
+func fireTusComplete(upload *TusUpload) {
+	for _, fn := range tusCompleteHandlers {
+		fn(upload)
+	}
+}
+
+func tusStore() ccachex.ICache {
+	if tusSettings != nil && tusSettings.Store() != "" {
+		return cachex.Store(tusSettings.Store())
+	}
+
+	return cachex.Store(cachex.DefaultStore())
+}
+
+func parseTusMetadata(header string) map[string]string {
+	map1 := map[string]string{}
+
+	for _, pair := range strings.Split(header, ",") {
+		parts := strings.Fields(pair)
+
+		if len(parts) < 1 {
+			continue
+		}
+
+		if len(parts) < 2 {
+			map1[parts[0]] = ""
+			continue
+		}
+
+		if buf, err := base64.StdEncoding.DecodeString(parts[1]); err == nil {
+			map1[parts[0]] = string(buf)
+		}
+	}
+
+	return map1
+}
+
+func encodeTusMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		if metadata[key] == "" {
+			pairs = append(pairs, key)
+			continue
+		}
+
+		pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(metadata[key])))
+	}
+
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,179 @@
+package mgboot
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/meiguonet/mgboot-go-gin/cachex"
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+type tusTestClient struct {
+	t      *testing.T
+	router *gin.Engine
+}
+
+func newTusTestClient(t *testing.T) *tusTestClient {
+	gin.SetMode(gin.TestMode)
+	cachex.WithMemoryCache(time.Minute, time.Minute)
+
+	WithTusSettings(map[string]interface{}{
+		"dir":     t.TempDir(),
+		"store":   "memory",
+		"maxSize": 100,
+	})
+
+	router := gin.New()
+	router.Use(MidRequestBody())
+	MountTusRoutes(router, "/files")
+	return &tusTestClient{t: t, router: router}
+}
+
+func (c *tusTestClient) do(method, target string, headers map[string]string, body io.Reader) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set("Tus-Resumable", tusVersion)
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	w := httptest.NewRecorder()
+	c.router.ServeHTTP(w, req)
+	return w
+}
+
+func (c *tusTestClient) patch(location, offset, body string) *httptest.ResponseRecorder {
+	return c.do("PATCH", location, map[string]string{
+		"Content-Type":  "application/offset+octet-stream",
+		"Upload-Offset": offset,
+	}, strings.NewReader(body))
+}
+
+func (c *tusTestClient) expect(name string, w *httptest.ResponseRecorder, code int, offset string) {
+	if w.Code != code {
+		c.t.Fatalf("%s: expected status %d, got %d", name, code, w.Code)
+	}
+
+	if offset != "" && w.Header().Get("Upload-Offset") != offset {
+		c.t.Fatalf("%s: expected Upload-Offset %s, got %q", name, offset, w.Header().Get("Upload-Offset"))
+	}
+}
+
+func isTusLockHeld(id string) bool {
+	_, ok := tusLocks.Load(id)
+	return ok
+}
+
+func TestTusUploadLifecycle(t *testing.T) {
+	c := newTusTestClient(t)
+	var completed *TusUpload
+	WithTusCompleteHandler(func(upload *TusUpload) { completed = upload })
+
+	w := c.do("OPTIONS", "/files", nil, nil)
+	c.expect("options", w, 204, "")
+
+	if w.Header().Get("Tus-Max-Size") != "100" {
+		t.Fatalf("unexpected Tus-Max-Size: %q", w.Header().Get("Tus-Max-Size"))
+	}
+
+	req := httptest.NewRequest("POST", "/files", nil)
+	req.Header.Set("Upload-Length", "10")
+	w = httptest.NewRecorder()
+	c.router.ServeHTTP(w, req)
+	c.expect("create without Tus-Resumable", w, 412, "")
+
+	c.expect("create too large", c.do("POST", "/files", map[string]string{"Upload-Length": "101"}, nil), 413, "")
+	c.expect("create without length", c.do("POST", "/files", nil, nil), 400, "")
+
+	w = c.do("POST", "/files", map[string]string{"Upload-Length": "10", "Upload-Metadata": "filename YS50eHQ="}, nil)
+	c.expect("create", w, 201, "0")
+	location := w.Header().Get("Location")
+	id := path.Base(location)
+
+	if GetTusUpload(id) == nil || GetTusUpload(id).Metadata["filename"] != "a.txt" {
+		t.Fatalf("upload should be stored with its metadata, location: %s", location)
+	}
+
+	c.expect("head", c.do("HEAD", location, nil, nil), 200, "0")
+	c.expect("wrong content type", c.do("PATCH", location, map[string]string{"Upload-Offset": "0"}, strings.NewReader("hello")), 415, "")
+	c.expect("patch", c.patch(location, "0", "hello"), 204, "5")
+	c.expect("stale offset", c.patch(location, "0", "hello"), 409, "")
+	c.expect("head after patch", c.do("HEAD", location, nil, nil), 200, "5")
+
+	if completed != nil {
+		t.Fatal("complete handler fired before the upload finished")
+	}
+
+	c.expect("final patch", c.patch(location, "5", "world"), 204, "10")
+
+	if completed == nil || completed.Id != id {
+		t.Fatal("complete handler should fire once the upload is finished")
+	}
+
+	buf, _ := ioutil.ReadFile(completed.FilePath)
+
+	if string(buf) != "helloworld" {
+		t.Fatalf("unexpected upload contents: %q", string(buf))
+	}
+
+	c.expect("patch after complete", c.patch(location, "10", "x"), 409, "")
+	c.expect("delete", c.do("DELETE", location, nil, nil), 204, "")
+	c.expect("head after delete", c.do("HEAD", location, nil, nil), 404, "")
+	c.expect("patch after delete", c.patch(location, "10", "x"), 404, "")
+	c.expect("delete after delete", c.do("DELETE", location, nil, nil), 404, "")
+
+	if isTusLockHeld(id) {
+		t.Fatal("lock of a deleted upload should be dropped")
+	}
+
+	c.expect("patch unknown upload", c.patch("/files/unknown", "0", "x"), 404, "")
+
+	if isTusLockHeld("unknown") {
+		t.Fatal("patching an unknown upload must not leave a lock behind")
+	}
+}
+
+func TestTusCreateWithUpload(t *testing.T) {
+	c := newTusTestClient(t)
+
+	w := c.do("POST", "/files", map[string]string{
+		"Upload-Length": "5",
+		"Content-Type":  "application/offset+octet-stream",
+	}, strings.NewReader("hello"))
+
+	c.expect("create with upload", w, 201, "5")
+}
+
+func TestTusPatchIgnoresMaxBodySize(t *testing.T) {
+	c := newTusTestClient(t)
+	orig := MaxBodySize()
+	MaxBodySize(4)
+	defer MaxBodySize(orig)
+
+	w := c.do("POST", "/files", map[string]string{"Upload-Length": "6"}, nil)
+	c.expect("create", w, 201, "0")
+	c.expect("patch over the body limit", c.patch(w.Header().Get("Location"), "0", "hello!"), 204, "6")
+}
+
+func TestTusPatchChunkTooLarge(t *testing.T) {
+	c := newTusTestClient(t)
+
+	WithTusSettings(map[string]interface{}{
+		"dir":          t.TempDir(),
+		"store":        "memory",
+		"maxSize":      100,
+		"maxChunkSize": 4,
+	})
+
+	w := c.do("POST", "/files", map[string]string{"Upload-Length": "10"}, nil)
+	c.expect("create", w, 201, "0")
+	location := w.Header().Get("Location")
+
+	c.expect("patch over the chunk limit", c.patch(location, "0", "hello!"), 413, "4")
+	c.expect("head after partial patch", c.do("HEAD", location, nil, nil), 200, "4")
+	c.expect("resume", c.patch(location, "4", "o!"), 204, "6")
+}
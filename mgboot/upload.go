@@ -0,0 +1,347 @@
+package mgboot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"github.com/meiguonet/mgboot-go-common/util/mimex"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const uploadFormValueMaxSize = 1024 * 1024
+
+var uploadStorages = map[string]UploadStorage{}
+var uploadStoragesLock = &sync.RWMutex{}
+
+func WithUploadStorage(name string, storage UploadStorage) {
+	if name == "" || storage == nil {
+		return
+	}
+
+	uploadStoragesLock.Lock()
+	defer uploadStoragesLock.Unlock()
+	uploadStorages[name] = storage
+}
+
+// the default storage falls back to the uploads directory under the data dir
+// when the application has not registered one
+func GetUploadStorage(name string) UploadStorage {
+	uploadStoragesLock.RLock()
+	storage := uploadStorages[name]
+	uploadStoragesLock.RUnlock()
+
+	if storage != nil || name != "default" || AppConf.GetDataDir() == "" {
+		return storage
+	}
+
+	uploadStoragesLock.Lock()
+	defer uploadStoragesLock.Unlock()
+
+	if uploadStorages[name] == nil {
+		uploadStorages[name] = NewLocalUploadStorage("datadir:uploads")
+	}
+
+	return uploadStorages[name]
+}
+
+// @param *UploadSettings|map[string]interface{} arg0
+func SaveUploadedFiles(req *Request, arg0 interface{}, fieldNames ...string) ([]*UploadedFile, error) {
+	var settings *UploadSettings
+
+	if st, ok := arg0.(*UploadSettings); ok && st != nil {
+		settings = st
+	} else if map1, ok := arg0.(map[string]interface{}); ok {
+		settings = NewUploadSettings(map1)
+	} else {
+		settings = NewUploadSettings(map[string]interface{}{})
+	}
+
+	storage := GetUploadStorage(settings.Storage())
+
+	if storage == nil && settings.Storage() == "default" {
+		return nil, fmt.Errorf("in mgboot.SaveUploadedFiles function, upload storage not found: default, register one with WithUploadStorage or set the data dir")
+	}
+
+	if storage == nil {
+		return nil, fmt.Errorf("in mgboot.SaveUploadedFiles function, upload storage not found: %s", settings.Storage())
+	}
+
+	if !strings.Contains(strings.ToLower(req.GetHeader("Content-Type")), "multipart/form-data") {
+		return nil, NewUploadError("not a multipart request")
+	}
+
+	bodyLimit := uploadBodyLimit(settings)
+
+	if req.ctx.Request.ContentLength > bodyLimit {
+		return nil, NewUploadError("request body too large", 413)
+	}
+
+	files := make([]*UploadedFile, 0)
+
+	if req.ctx.Request.MultipartForm != nil {
+		names := make([]string, 0, len(req.ctx.Request.MultipartForm.File))
+
+		for name := range req.ctx.Request.MultipartForm.File {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			if !isUploadFieldWanted(name, fieldNames) {
+				continue
+			}
+
+			for _, fh := range req.ctx.Request.MultipartForm.File[name] {
+				if len(files) >= settings.MaxFileCount() {
+					return files, NewUploadError("too many files")
+				}
+
+				fd, err := fh.Open()
+
+				if err != nil {
+					return files, err
+				}
+
+				file, err := storeUploadedFile(settings, storage, name, fh.Filename, fd)
+				fd.Close()
+
+				if err != nil {
+					return files, err
+				}
+
+				files = append(files, file)
+			}
+		}
+
+		return files, nil
+	}
+
+	limitRequestBodySize(req.ctx, bodyLimit)
+	reader, err := req.ctx.Request.MultipartReader()
+
+	if err != nil {
+		return nil, NewUploadError(err.Error())
+	}
+
+	if req.ctx.Request.PostForm == nil {
+		req.ctx.Request.PostForm = url.Values{}
+	}
+
+	if req.ctx.Request.Form == nil {
+		req.ctx.Request.Form = url.Values{}
+	}
+
+	for {
+		part, err := reader.NextPart()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil && isBodyLimitExceeded(req.ctx) {
+			return files, NewUploadError("request body too large", 413)
+		}
+
+		if err != nil {
+			return files, NewUploadError(err.Error())
+		}
+
+		name := part.FormName()
+
+		if part.FileName() == "" {
+			buf, err := ioutil.ReadAll(io.LimitReader(part, uploadFormValueMaxSize+1))
+
+			if err != nil {
+				part.Close()
+				return files, NewUploadError(err.Error())
+			}
+
+			if len(buf) > uploadFormValueMaxSize {
+				part.Close()
+				return files, NewUploadError("form value too large: "+name, 413)
+			}
+
+			req.ctx.Request.PostForm.Add(name, string(buf))
+			req.ctx.Request.Form.Add(name, string(buf))
+			part.Close()
+			continue
+		}
+
+		if !isUploadFieldWanted(name, fieldNames) {
+			part.Close()
+			continue
+		}
+
+		if len(files) >= settings.MaxFileCount() {
+			part.Close()
+			return files, NewUploadError("too many files")
+		}
+
+		file, err := storeUploadedFile(settings, storage, name, part.FileName(), part)
+		part.Close()
+
+		if err != nil {
+			return files, err
+		}
+
+		files = append(files, file)
+	}
+
+	req.ctx.Set("requestParsedMap", nil)
+	return files, nil
+}
+
+// without a global body limit the upload is limited to the allowed files plus
+// one form value worth of room for the other fields and the part headers
+func uploadBodyLimit(settings *UploadSettings) int64 {
+	if maxBodySize > 0 {
+		return maxBodySize
+	}
+
+	return settings.MaxFileSize()*int64(settings.MaxFileCount()) + uploadFormValueMaxSize
+}
+
+func isUploadFieldWanted(name string, fieldNames []string) bool {
+	if len(fieldNames) < 1 {
+		return true
+	}
+
+	for _, s1 := range fieldNames {
+		if s1 == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func storeUploadedFile(settings *UploadSettings, storage UploadStorage, fieldName, filename string, reader io.Reader) (*UploadedFile, error) {
+	filename = SanitizeFilename(filename)
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+
+	if !settings.IsExtensionAllowed(ext) {
+		return nil, NewUploadError("file extension not allowed: "+ext, 415)
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(reader, head)
+
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+
+	head = head[:n]
+	mimeType := strings.TrimSpace(strings.Split(mimex.GetMimeType(head), ";")[0])
+
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	if !settings.IsMimeTypeAllowed(mimeType) {
+		return nil, NewUploadError("file type not allowed: "+mimeType, 415)
+	}
+
+	tmp, err := ioutil.TempFile("", "mgboot-upload-*")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	hash := sha256.New()
+	writer := io.MultiWriter(tmp, hash)
+
+	if _, err := writer.Write(head); err != nil {
+		return nil, err
+	}
+
+	copied, err := io.Copy(writer, io.LimitReader(reader, settings.MaxFileSize()-int64(len(head))+1))
+
+	if err != nil {
+		return nil, NewUploadError(err.Error())
+	}
+
+	size := int64(len(head)) + copied
+
+	if size > settings.MaxFileSize() {
+		return nil, NewUploadError("file too large: "+filename, 413)
+	}
+
+	digest := hex.EncodeToString(hash.Sum(nil))
+	key := buildUploadKey(settings, digest, ext)
+
+	file := &UploadedFile{
+		FieldName:    fieldName,
+		OriginalName: filename,
+		Extension:    ext,
+		MimeType:     mimeType,
+		Size:         size,
+		Hash:         digest,
+		Key:          key,
+		Url:          storage.Url(key),
+	}
+
+	if settings.Dedupe() && storage.Exists(key) {
+		file.Deduplicated = true
+		return file, nil
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	if err := storage.Save(key, tmp, size, mimeType); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func buildUploadKey(settings *UploadSettings, digest, ext string) string {
+	var key string
+
+	if settings.Dedupe() {
+		key = digest[:2] + "/" + digest
+	} else {
+		key = time.Now().Format("2006/01/02") + "/" + randomHexString(16)
+	}
+
+	if ext != "" {
+		key += "." + ext
+	}
+
+	if settings.KeyPrefix() != "" {
+		key = settings.KeyPrefix() + "/" + key
+	}
+
+	return key
+}
+
+func getUploadedFileHeaders(req *Request, fieldName string) []*multipart.FileHeader {
+	if _, err := req.ctx.MultipartForm(); err != nil {
+		return make([]*multipart.FileHeader, 0)
+	}
+
+	headers := req.ctx.Request.MultipartForm.File[fieldName]
+
+	if len(headers) < 1 {
+		return make([]*multipart.FileHeader, 0)
+	}
+
+	return headers
+}
@@ -0,0 +1,109 @@
+package mgboot
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/meiguonet/mgboot-go-common/AppConf"
+	"mime/multipart"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newUploadTestRequest(t *testing.T, fields map[string]string, files map[string]string) *Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	for name, value := range fields {
+		writer.WriteField(name, value)
+	}
+
+	for name, contents := range files {
+		part, err := writer.CreateFormFile(name, name+".txt")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		part.Write([]byte(contents))
+	}
+
+	writer.Close()
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("POST", "/", body)
+	ctx.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	return NewRequest(ctx)
+}
+
+func TestSaveUploadedFilesStreaming(t *testing.T) {
+	WithUploadStorage("test", NewLocalUploadStorage(t.TempDir()))
+	settings := map[string]interface{}{"storage": "test"}
+	req := newUploadTestRequest(t, map[string]string{"title": "hello"}, map[string]string{"file": "plain text contents"})
+	files, err := SaveUploadedFiles(req, settings)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) != 1 || files[0].FieldName != "file" || files[0].Size != int64(len("plain text contents")) {
+		t.Fatalf("unexpected files: %+v", files)
+	}
+
+	if req.GetFormValues().Get("title") != "hello" {
+		t.Fatal("form values should be collected while streaming")
+	}
+
+	req = newUploadTestRequest(t, map[string]string{"title": strings.Repeat("a", uploadFormValueMaxSize+1)}, nil)
+	_, err = SaveUploadedFiles(req, settings)
+
+	if ex, ok := err.(UploadError); !ok || ex.StatusCode() != 413 {
+		t.Fatalf("expected an oversized form value to be rejected with 413, got %v", err)
+	}
+}
+
+func TestSaveUploadedFilesBodyLimit(t *testing.T) {
+	WithUploadStorage("test", NewLocalUploadStorage(t.TempDir()))
+	contents := strings.Repeat("a", 11<<20)
+	settings := map[string]interface{}{"storage": "test", "maxFileSize": 12 << 20, "maxFileCount": 1}
+	files, err := SaveUploadedFiles(newUploadTestRequest(t, nil, map[string]string{"file": contents}), settings)
+
+	if err != nil || len(files) != 1 || files[0].Size != int64(len(contents)) {
+		t.Fatalf("an upload within the settings should not be limited by a default body size, got %+v %v", files, err)
+	}
+
+	settings["maxFileSize"] = 1 << 20
+	_, err = SaveUploadedFiles(newUploadTestRequest(t, nil, map[string]string{"file": contents}), settings)
+
+	if ex, ok := err.(UploadError); !ok || ex.StatusCode() != 413 {
+		t.Fatalf("expected a body over maxFileSize * maxFileCount to be rejected with 413, got %v", err)
+	}
+}
+
+func TestSaveUploadedFilesDefaultStorage(t *testing.T) {
+	origDataDir := AppConf.GetDataDir()
+	dataDir := t.TempDir()
+	AppConf.SetDataDir(dataDir)
+
+	defer func() {
+		uploadStoragesLock.Lock()
+		delete(uploadStorages, "default")
+		uploadStoragesLock.Unlock()
+
+		if origDataDir != "" {
+			AppConf.SetDataDir(origDataDir)
+		}
+	}()
+
+	files, err := SaveUploadedFiles(newUploadTestRequest(t, nil, map[string]string{"file": "contents"}), nil)
+
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected the default storage to be registered on first use, got %+v %v", files, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dataDir, "uploads", files[0].Key)); err != nil {
+		t.Fatalf("expected the file under the uploads data dir: %v", err)
+	}
+}
@@ -0,0 +1,416 @@
+package mgboot
+
+import (
+	"fmt"
+	"github.com/go-errors/errors"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"github.com/meiguonet/mgboot-go-common/util/validatex"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+type dtoValidateRule struct {
+	name  string
+	param string
+}
+
+// validatex.IsEmail rejects every domain in mgboot-go-common v1.0.9, so the email rule has its own pattern
+var dtoEmailRegex = regexp.MustCompile(`^[^\s@]+@[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?)*\.[A-Za-z]{2,}$`)
+var dtoRegexps = map[string]*regexp.Regexp{}
+var dtoRegexpsLock = &sync.RWMutex{}
+
+func ValidateDto(dto interface{}, failfast ...bool) error {
+	rv := reflect.ValueOf(dto)
+
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return errors.New("in mgboot.ValidateDto function, argument #0 must be a struct or struct pointer")
+		}
+
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return errors.New("in mgboot.ValidateDto function, argument #0 must be a struct or struct pointer")
+	}
+
+	validateErrors := map[string]string{}
+	validateDtoStruct(rv, "", validateErrors)
+
+	if len(validateErrors) < 1 {
+		return nil
+	}
+
+	if len(failfast) > 0 && failfast[0] {
+		rt := rv.Type()
+
+		keys := make([]string, 0, len(validateErrors))
+
+		for key := range validateErrors {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		// report the first failing field in declaration order, nested errors such as
+		// items[0].name belong to their top-level field
+		for i := 0; i < rt.NumField(); i++ {
+			name := bindingFieldName(rt.Field(i))
+
+			for _, key := range keys {
+				if key == name || strings.HasPrefix(key, name+"[") || strings.HasPrefix(key, name+".") {
+					return NewValidateError(validateErrors[key], true)
+				}
+			}
+		}
+
+		return NewValidateError(validateErrors[keys[0]], true)
+	}
+
+	return NewValidateError(validateErrors)
+}
+
+func validateDtoStruct(rv reflect.Value, prefix string, validateErrors map[string]string) {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fv := rv.Field(i)
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			validateDtoStruct(fv, prefix, validateErrors)
+			continue
+		}
+
+		name := bindingFieldName(field)
+
+		if name == "-" {
+			continue
+		}
+
+		key := prefix + name
+		rules := parseDtoValidateRules(field.Tag.Get("validate"))
+		msg := field.Tag.Get("msg")
+
+		if errorTips := validateDtoField(rv, fv, rules, msg); errorTips != "" {
+			validateErrors[key] = errorTips
+			continue
+		}
+
+		if idx := indexOfDtoRule(rules, "dive"); idx >= 0 {
+			elemRules := rules[idx+1:]
+
+			for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+
+			if fv.Kind() != reflect.Slice && fv.Kind() != reflect.Array {
+				continue
+			}
+
+			for j := 0; j < fv.Len(); j++ {
+				elem := fv.Index(j)
+				elemKey := fmt.Sprintf("%s[%d]", key, j)
+
+				if errorTips := validateDtoField(rv, elem, elemRules, msg); errorTips != "" {
+					validateErrors[elemKey] = errorTips
+					continue
+				}
+
+				for elem.Kind() == reflect.Ptr && !elem.IsNil() {
+					elem = elem.Elem()
+				}
+
+				if elem.Kind() == reflect.Struct && elem.Type() != timeType {
+					validateDtoStruct(elem, elemKey+".", validateErrors)
+				}
+			}
+
+			continue
+		}
+
+		for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+
+		if fv.Kind() == reflect.Struct && fv.Type() != timeType {
+			validateDtoStruct(fv, key+".", validateErrors)
+		}
+	}
+}
+
+func parseDtoValidateRules(tag string) []dtoValidateRule {
+	rules := make([]dtoValidateRule, 0)
+
+	for tag != "" {
+		var part string
+
+		if strings.HasPrefix(tag, "regex=") {
+			part, tag = tag, ""
+		} else if idx := strings.Index(tag, ","); idx >= 0 {
+			part, tag = tag[:idx], tag[idx+1:]
+		} else {
+			part, tag = tag, ""
+		}
+
+		part = strings.TrimSpace(part)
+
+		if part == "" {
+			continue
+		}
+
+		if idx := strings.Index(part, "="); idx > 0 {
+			rules = append(rules, dtoValidateRule{name: part[:idx], param: part[idx+1:]})
+		} else {
+			rules = append(rules, dtoValidateRule{name: part})
+		}
+	}
+
+	return rules
+}
+
+func indexOfDtoRule(rules []dtoValidateRule, name string) int {
+	for idx, rule := range rules {
+		if rule.name == name {
+			return idx
+		}
+	}
+
+	return -1
+}
+
+func validateDtoField(parent, fv reflect.Value, rules []dtoValidateRule, msg string) string {
+	if len(rules) < 1 {
+		return ""
+	}
+
+	for fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+		if fv.IsNil() {
+			break
+		}
+
+		fv = fv.Elem()
+	}
+
+	isEmpty := isEmptyDtoValue(fv)
+
+	for _, rule := range rules {
+		if rule.name == "dive" {
+			break
+		}
+
+		if rule.name == "required" {
+			if isEmpty {
+				return dtoValidateMessage(msg, "必须填写")
+			}
+
+			continue
+		}
+
+		if isEmpty {
+			continue
+		}
+
+		if errorTips := checkDtoRule(parent, fv, rule); errorTips != "" {
+			return dtoValidateMessage(msg, errorTips)
+		}
+	}
+
+	return ""
+}
+
+func isEmptyDtoValue(fv reflect.Value) bool {
+	if !fv.IsValid() {
+		return true
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(fv.String()) == ""
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return fv.Len() < 1
+	case reflect.Ptr, reflect.Interface:
+		return fv.IsNil()
+	case reflect.Struct:
+		if fv.Type() == timeType {
+			return fv.Interface().(time.Time).IsZero()
+		}
+
+		return false
+	case reflect.Bool:
+		return false
+	}
+
+	return fv.IsZero()
+}
+
+func checkDtoRule(parent, fv reflect.Value, rule dtoValidateRule) string {
+	validator := validatex.NewValidator()
+
+	switch rule.name {
+	case "min", "max", "len":
+		expected := castx.ToFloat64(rule.param)
+		var actual float64
+		var isSize bool
+
+		switch fv.Kind() {
+		case reflect.String:
+			actual = float64(utf8.RuneCountInString(fv.String()))
+			isSize = true
+		case reflect.Slice, reflect.Map, reflect.Array:
+			actual = float64(fv.Len())
+			isSize = true
+		default:
+			actual = castx.ToFloat64(fv.Interface())
+		}
+
+		switch {
+		case rule.name == "min" && actual < expected:
+			if isSize {
+				return fmt.Sprintf("长度不能小于%s", rule.param)
+			}
+
+			return fmt.Sprintf("不能小于%s", rule.param)
+		case rule.name == "max" && actual > expected:
+			if isSize {
+				return fmt.Sprintf("长度不能大于%s", rule.param)
+			}
+
+			return fmt.Sprintf("不能大于%s", rule.param)
+		case rule.name == "len" && actual != expected:
+			return fmt.Sprintf("长度必须为%s", rule.param)
+		}
+	case "regex":
+		re := getDtoRegexp(rule.param)
+
+		if re == nil || !re.MatchString(castx.ToString(fv.Interface())) {
+			return "格式不正确"
+		}
+	case "email":
+		if !dtoEmailRegex.MatchString(castx.ToString(fv.Interface())) {
+			return "不是有效的邮箱地址"
+		}
+	case "mobile":
+		if !validator.IsMobile(castx.ToString(fv.Interface())) {
+			return "不是有效的手机号码"
+		}
+	case "idcard":
+		if !validator.IsIdcard(castx.ToString(fv.Interface())) {
+			return "不是有效的身份证号码"
+		}
+	case "oneof":
+		value := castx.ToString(fv.Interface())
+
+		for _, s1 := range strings.Fields(rule.param) {
+			if s1 == value {
+				return ""
+			}
+		}
+
+		return fmt.Sprintf("必须是[%s]之一", strings.Join(strings.Fields(rule.param), ", "))
+	case "eqfield", "nefield", "gtfield", "gtefield", "ltfield", "ltefield":
+		other := parent.FieldByName(rule.param)
+
+		for other.IsValid() && other.Kind() == reflect.Ptr && !other.IsNil() {
+			other = other.Elem()
+		}
+
+		if !other.IsValid() {
+			return ""
+		}
+
+		cmp, ok := compareDtoValues(fv, other)
+
+		if !ok {
+			return "无法比较"
+		}
+
+		switch {
+		case rule.name == "eqfield" && cmp != 0:
+			return fmt.Sprintf("必须与%s一致", rule.param)
+		case rule.name == "nefield" && cmp == 0:
+			return fmt.Sprintf("不能与%s相同", rule.param)
+		case rule.name == "gtfield" && cmp <= 0:
+			return fmt.Sprintf("必须大于%s", rule.param)
+		case rule.name == "gtefield" && cmp < 0:
+			return fmt.Sprintf("不能小于%s", rule.param)
+		case rule.name == "ltfield" && cmp >= 0:
+			return fmt.Sprintf("必须小于%s", rule.param)
+		case rule.name == "ltefield" && cmp > 0:
+			return fmt.Sprintf("不能大于%s", rule.param)
+		}
+	}
+
+	return ""
+}
+
+func compareDtoValues(a, b reflect.Value) (int, bool) {
+	if a.Type() == timeType && b.Type() == timeType {
+		t1 := a.Interface().(time.Time)
+		t2 := b.Interface().(time.Time)
+
+		switch {
+		case t1.Before(t2):
+			return -1, true
+		case t1.After(t2):
+			return 1, true
+		}
+
+		return 0, true
+	}
+
+	if a.Kind() == reflect.String || b.Kind() == reflect.String {
+		s1 := castx.ToString(a.Interface())
+		s2 := castx.ToString(b.Interface())
+		return strings.Compare(s1, s2), true
+	}
+
+	n1, err1 := castx.ToFloat64E(a.Interface())
+	n2, err2 := castx.ToFloat64E(b.Interface())
+
+	if err1 != nil || err2 != nil {
+		return 0, false
+	}
+
+	switch {
+	case n1 < n2:
+		return -1, true
+	case n1 > n2:
+		return 1, true
+	}
+
+	return 0, true
+}
+
+func getDtoRegexp(pattern string) *regexp.Regexp {
+	dtoRegexpsLock.RLock()
+	re, ok := dtoRegexps[pattern]
+	dtoRegexpsLock.RUnlock()
+
+	if ok {
+		return re
+	}
+
+	re, _ = regexp.Compile(pattern)
+	dtoRegexpsLock.Lock()
+	dtoRegexps[pattern] = re
+	dtoRegexpsLock.Unlock()
+	return re
+}
+
+func dtoValidateMessage(msg, defaultMsg string) string {
+	if msg != "" {
+		return msg
+	}
+
+	return defaultMsg
+}
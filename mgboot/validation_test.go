@@ -0,0 +1,149 @@
+package mgboot
+
+import (
+	"github.com/meiguonet/mgboot-go-common/util/jsonx"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type validationItemDto struct {
+	Name string `json:"name" validate:"required,max=4"`
+}
+
+type validationDto struct {
+	Name      string              `json:"name" validate:"required,min=2,max=4"`
+	Age       int                 `json:"age" validate:"min=18,max=60"`
+	Code      string              `json:"code" validate:"len=3"`
+	Tags      []string            `json:"tags" validate:"min=1,max=2"`
+	Slug      string              `json:"slug" validate:"regex=^[a-z]{2,3},[0-9]+$"`
+	Email     string              `json:"email" validate:"email"`
+	Mobile    string              `json:"mobile" validate:"mobile"`
+	Idcard    string              `json:"idcard" validate:"idcard"`
+	Status    string              `json:"status" validate:"oneof=draft published"`
+	Password  string              `json:"password" validate:"required"`
+	Password2 string              `json:"password2" validate:"eqfield=Password" msg:"两次密码不一致"`
+	StartAt   time.Time           `json:"startAt"`
+	EndAt     time.Time           `json:"endAt" validate:"gtfield=StartAt"`
+	MinPrice  float64             `json:"minPrice"`
+	MaxPrice  float64             `json:"maxPrice" validate:"gtefield=MinPrice"`
+	Items     []validationItemDto `json:"items" validate:"dive"`
+	Codes     []string            `json:"codes" validate:"dive,len=2"`
+	Owner     *validationItemDto  `json:"owner"`
+	Ignored   string              `json:"-" validate:"required"`
+}
+
+func newValidValidationDto() validationDto {
+	now := time.Now()
+
+	return validationDto{
+		Name:      "tom",
+		Age:       30,
+		Code:      "abc",
+		Tags:      []string{"a"},
+		Slug:      "ab,12",
+		Email:     "tom@example.com",
+		Mobile:    "13800138000",
+		Idcard:    "11010519491231002X",
+		Status:    "draft",
+		Password:  "secret",
+		Password2: "secret",
+		StartAt:   now,
+		EndAt:     now.Add(time.Hour),
+		MinPrice:  1,
+		MaxPrice:  1,
+		Items:     []validationItemDto{{Name: "a"}},
+		Codes:     []string{"aa"},
+		Owner:     &validationItemDto{Name: "bob"},
+	}
+}
+
+func TestValidateDto(t *testing.T) {
+	if err := ValidateDto(newValidValidationDto()); err != nil {
+		t.Fatalf("valid dto rejected: %v", err.(ValidateError).ValidateErrors())
+	}
+
+	cases := []struct {
+		name   string
+		modify func(dto *validationDto)
+		key    string
+		msg    string
+	}{
+		{"required", func(dto *validationDto) { dto.Name = "  " }, "name", "必须填写"},
+		{"min string counts runes", func(dto *validationDto) { dto.Name = "张" }, "name", "长度不能小于2"},
+		{"max string counts runes", func(dto *validationDto) { dto.Name = "张三李四王" }, "name", "长度不能大于4"},
+		{"min number", func(dto *validationDto) { dto.Age = 17 }, "age", "不能小于18"},
+		{"max number", func(dto *validationDto) { dto.Age = 61 }, "age", "不能大于60"},
+		{"zero number skips min", func(dto *validationDto) { dto.Age = 0 }, "", ""},
+		{"len", func(dto *validationDto) { dto.Code = "abcd" }, "code", "长度必须为3"},
+		{"max slice", func(dto *validationDto) { dto.Tags = []string{"a", "b", "c"} }, "tags", "长度不能大于2"},
+		{"regex with comma", func(dto *validationDto) { dto.Slug = "ab12" }, "slug", "格式不正确"},
+		{"email", func(dto *validationDto) { dto.Email = "tom@" }, "email", "不是有效的邮箱地址"},
+		{"mobile", func(dto *validationDto) { dto.Mobile = "12345" }, "mobile", "不是有效的手机号码"},
+		{"idcard", func(dto *validationDto) { dto.Idcard = "110105194912310021" }, "idcard", "不是有效的身份证号码"},
+		{"oneof", func(dto *validationDto) { dto.Status = "deleted" }, "status", "必须是[draft, published]之一"},
+		{"eqfield with msg", func(dto *validationDto) { dto.Password2 = "other" }, "password2", "两次密码不一致"},
+		{"gtfield time", func(dto *validationDto) { dto.EndAt = dto.StartAt }, "endAt", "必须大于StartAt"},
+		{"gtefield number", func(dto *validationDto) { dto.MaxPrice = 0.5 }, "maxPrice", "不能小于MinPrice"},
+		{"dive struct", func(dto *validationDto) { dto.Items = append(dto.Items, validationItemDto{}) }, "items[1].name", "必须填写"},
+		{"dive scalar", func(dto *validationDto) { dto.Codes = []string{"aa", "b"} }, "codes[1]", "长度必须为2"},
+		{"nested struct", func(dto *validationDto) { dto.Owner.Name = "alice" }, "owner.name", "长度不能大于4"},
+		{"nil nested struct", func(dto *validationDto) { dto.Owner = nil }, "", ""},
+	}
+
+	for _, c := range cases {
+		dto := newValidValidationDto()
+		c.modify(&dto)
+		err := ValidateDto(&dto)
+
+		if c.key == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", c.name, err.(ValidateError).ValidateErrors())
+			}
+
+			continue
+		}
+
+		ex, ok := err.(ValidateError)
+		expected := map[string]string{c.key: c.msg}
+
+		if !ok || !reflect.DeepEqual(ex.ValidateErrors(), expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, expected, err)
+		}
+	}
+}
+
+func TestValidateDtoFailfast(t *testing.T) {
+	dto := newValidValidationDto()
+	dto.Items = []validationItemDto{{Name: "a"}, {Name: "toolong"}}
+	dto.Owner.Name = ""
+	dto.Codes = []string{"x"}
+
+	for i := 0; i < 10; i++ {
+		err := ValidateDto(&dto, true)
+		ex, ok := err.(ValidateError)
+
+		if !ok || !ex.Failfast() || ex.Error() != "长度不能大于4" {
+			t.Fatalf("failfast must report the first failing field in declaration order, got %v", err)
+		}
+	}
+
+	dto.Name = ""
+
+	if err := ValidateDto(&dto, true); err == nil || err.Error() != "必须填写" {
+		t.Fatalf("expected the name error first, got %v", err)
+	}
+}
+
+func TestValidateErrorHandlerKeepsFieldKeys(t *testing.T) {
+	dto := newValidValidationDto()
+	dto.Items = []validationItemDto{{}}
+	err := ValidateDto(&dto)
+	payload := NewValidateErrorHandler().HandleError(err).(JsonResponse)
+	msg := jsonx.ToJson(payload.payload)
+
+	if !strings.Contains(msg, `items[0].name`) {
+		t.Fatalf("rendered errors should keep the json field path, got %s", msg)
+	}
+}